@@ -1,15 +1,24 @@
 package arrest
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+	"regexp"
 	"slices"
 	"strings"
 
 	"github.com/pb33f/libopenapi"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
 	"github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/json"
 	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
 )
 
 type PackageMap struct {
@@ -29,6 +38,10 @@ type Document struct {
 	// used in SchemaComponentRef.
 	PkgMap []PackageMap
 
+	operationHooks   []func(method, pattern string, o *Operation)
+	schemaTransforms []func(name string, s *base.Schema)
+	componentNameSep string
+
 	ErrHelper
 }
 
@@ -56,10 +69,32 @@ func NewDocumentFrom(doc libopenapi.Document) (*Document, error) {
 	}, nil
 }
 
-// NewDocument creates a new Document with the given title.
+// NewDocument creates a new OpenAPI 3.1.0 Document with the given title.
 func NewDocument(title string) (*Document, error) {
+	return NewDocumentWithVersion(title, "3.1.0")
+}
+
+// NewDocumentWithVersion creates a new Document with the given title that
+// targets the given version of OpenAPI. Models are always generated in the
+// OpenAPI 3.1 style, but when a 3.0.x version is given, the document is
+// downgraded as it is rendered by Render or RenderJSON:
+//
+//   - A type list including "null" becomes the remaining type with
+//     nullable: true.
+//   - A numeric exclusiveMinimum or exclusiveMaximum becomes a minimum or
+//     maximum with a boolean exclusiveMinimum or exclusiveMaximum.
+//
+// Other features only supported by 3.1, such as webhooks, const, a type list
+// naming more than one type other than "null", schema examples lists, and
+// dependentRequired, are rendered unchanged and should be avoided in 3.0.x
+// documents.
+//
+// Only Render and RenderJSON downgrade the document and apply the functions
+// registered with SchemaTransform. Rendering the underlying document directly
+// with doc.OpenAPI.Render() skips both.
+func NewDocumentWithVersion(title, version string) (*Document, error) {
 	doc := &v3.Document{
-		Version: "3.1.0",
+		Version: version,
 		Info: &base.Info{
 			Title: title,
 		},
@@ -84,6 +119,483 @@ func (d *Document) Refresh() error {
 	return nil
 }
 
+// SchemaTransform registers a function that is called with the name and schema
+// of every schema component each time the document is rendered. This is useful
+// for applying conventions to every generated schema, such as adding a vendor
+// extension. Because the transform runs on every render, it must be safe to
+// apply to a schema more than once. Transforms are run in the order they are
+// registered.
+func (d *Document) SchemaTransform(transform func(name string, s *base.Schema)) *Document {
+	d.schemaTransforms = append(d.schemaTransforms, transform)
+	return d
+}
+
+// applySchemaTransforms runs the schema transforms on the schema components.
+// Components that are only references to other schemas are skipped.
+func (d *Document) applySchemaTransforms() {
+	if len(d.schemaTransforms) == 0 || d.DataModel.Model.Components == nil ||
+		d.DataModel.Model.Components.Schemas == nil {
+		return
+	}
+
+	for name, sp := range d.DataModel.Model.Components.Schemas.FromOldest() {
+		if sp.IsReference() {
+			continue
+		}
+
+		for _, transform := range d.schemaTransforms {
+			transform(name, sp.Schema())
+		}
+	}
+}
+
+// isOpenAPI30 returns true if the document targets OpenAPI 3.0.x.
+func (d *Document) isOpenAPI30() bool {
+	return strings.HasPrefix(d.DataModel.Model.Version, "3.0")
+}
+
+// downgradedNode renders the document and returns it as a YAML node with the
+// constructs only supported by OpenAPI 3.1 converted for OpenAPI 3.0.
+func (d *Document) downgradedNode() (*yaml.Node, error) {
+	bs, err := d.OpenAPI.Render()
+	if err != nil {
+		return nil, err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(bs, &node); err != nil {
+		return nil, err
+	}
+
+	downgradeNode(&node)
+
+	return &node, nil
+}
+
+// Render renders the document as YAML.
+func (d *Document) Render() ([]byte, error) {
+	d.applySchemaTransforms()
+
+	if !d.isOpenAPI30() {
+		return d.OpenAPI.Render()
+	}
+
+	node, err := d.downgradedNode()
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(node)
+}
+
+// RenderJSON renders the document as JSON, suitable for serving as
+// openapi.json.
+func (d *Document) RenderJSON() ([]byte, error) {
+	d.applySchemaTransforms()
+
+	if !d.isOpenAPI30() {
+		return d.DataModel.Model.RenderJSON("  ")
+	}
+
+	node, err := d.downgradedNode()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.YAMLNodeToJSON(node, "  ")
+}
+
+// downgradeNode converts the constructs only supported by OpenAPI 3.1 found
+// in the node tree to their OpenAPI 3.0 equivalents. Only values of the kind
+// used by 3.1 are converted, so properties or components that happen to share
+// a name with one of these keywords are left alone, as are literal values such
+// as examples and extensions.
+func downgradeNode(n *yaml.Node) {
+	if n.Kind == yaml.MappingNode {
+		var typeNode, nullableNode *yaml.Node
+		isNullable := false
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			switch {
+			case key.Value == "type" && value.Kind == yaml.SequenceNode:
+				typeNode = value
+			case key.Value == "nullable":
+				nullableNode = value
+			case (key.Value == "anyOf" || key.Value == "oneOf") && value.Kind == yaml.SequenceNode:
+				// a nullable reference is an anyOf with a null schema in 3.1
+				schemas := slices.DeleteFunc(slices.Clone(value.Content), isNullSchema)
+				if len(schemas) < len(value.Content) {
+					value.Content = schemas
+					isNullable = true
+				}
+			case (key.Value == "exclusiveMinimum" || key.Value == "exclusiveMaximum") &&
+				value.Kind == yaml.ScalarNode && value.Tag != "!!bool":
+				bound := "minimum"
+				if key.Value == "exclusiveMaximum" {
+					bound = "maximum"
+				}
+
+				setMappingValue(n, bound, &yaml.Node{Kind: yaml.ScalarNode, Tag: value.Tag, Value: value.Value})
+				n.Content[i+1] = utils.CreateBoolNode("true")
+			}
+		}
+
+		if typeNode != nil {
+			types := make([]*yaml.Node, 0, len(typeNode.Content))
+			hasNull := false
+			for _, t := range typeNode.Content {
+				if t.Value == "null" {
+					hasNull = true
+					continue
+				}
+				types = append(types, t)
+			}
+
+			if hasNull {
+				typeNode.Content = types
+				if len(types) == 1 {
+					*typeNode = *types[0]
+				}
+
+				isNullable = true
+			}
+		}
+
+		if isNullable && nullableNode == nil {
+			n.Content = append(n.Content, utils.CreateStringNode("nullable"), utils.CreateBoolNode("true"))
+		}
+
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if !valueKeys[key.Value] && !strings.HasPrefix(key.Value, "x-") {
+				downgradeNode(value)
+			}
+		}
+
+		return
+	}
+
+	for _, c := range n.Content {
+		downgradeNode(c)
+	}
+}
+
+// isNullSchema returns true if the node is the schema {type: "null"}.
+func isNullSchema(n *yaml.Node) bool {
+	return n.Kind == yaml.MappingNode && len(n.Content) == 2 &&
+		n.Content[0].Value == "type" && n.Content[1].Value == "null"
+}
+
+// setMappingValue sets the value of the key in the mapping node, adding the
+// key if it is not already present.
+func setMappingValue(n *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			n.Content[i+1] = value
+			return
+		}
+	}
+
+	n.Content = append(n.Content, utils.CreateStringNode(key), value)
+}
+
+// RenderCompact renders the document as YAML with empty documentation removed:
+// descriptions, summaries, and titles that are empty or null and empty entries
+// in lists of tags. This reduces the size of the output for delivery over the
+// network. Everything else is left as is, including empty objects, such as an
+// empty schema, and schema values, such as a default of "".
+func (d *Document) RenderCompact() ([]byte, error) {
+	bs, err := d.Render()
+	if err != nil {
+		return nil, err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(bs, &node); err != nil {
+		return nil, err
+	}
+
+	compactNode(&node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// References lists every $ref used anywhere in the document, including the
+// paths and the components, in the order they first appear. Each reference is
+// listed only once. It returns nil if the document cannot be rendered.
+func (d *Document) References(ctx context.Context) []string {
+	bs, err := d.Render()
+	if err != nil {
+		return nil
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(bs, &node); err != nil {
+		return nil
+	}
+
+	var refs []string
+	seen := map[string]struct{}{}
+	walkRefs(ctx, &node, func(ref string) {
+		if _, isSeen := seen[ref]; !isSeen {
+			seen[ref] = struct{}{}
+			refs = append(refs, ref)
+		}
+	})
+
+	return refs
+}
+
+// walkRefs calls the function with the value of every $ref found in the node
+// tree, in the order they appear.
+func walkRefs(ctx context.Context, n *yaml.Node, fn func(ref string)) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if key.Value == "$ref" && value.Kind == yaml.ScalarNode {
+				fn(value.Value)
+			}
+		}
+	}
+
+	for _, c := range n.Content {
+		walkRefs(ctx, c, fn)
+	}
+}
+
+// operationKeys are the keys of a path item that hold operations.
+var operationKeys = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// FilterByTag returns a new document holding only the operations of this
+// document that are tagged with at least one of the given tags. Path items and
+// webhooks left without operations are removed, as are the tag definitions and
+// the components that the remaining operations do not use, directly or
+// through other components. Security schemes are always kept. The settings of
+// the document, such as the package map and the model options, are copied to
+// the new document.
+func (d *Document) FilterByTag(tags ...string) (*Document, error) {
+	d.applySchemaTransforms()
+
+	bs, err := d.OpenAPI.Render()
+	if err != nil {
+		return nil, err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(bs, &node); err != nil {
+		return nil, err
+	}
+
+	root := node.Content[0]
+
+	usedTags := map[string]bool{}
+	for _, section := range []string{"paths", "webhooks"} {
+		if pis := mappingValue(root, section); pis != nil {
+			filterPathItems(pis, tags, usedTags)
+		}
+	}
+
+	if defs := mappingValue(root, "tags"); defs != nil {
+		kept := make([]*yaml.Node, 0, len(defs.Content))
+		for _, def := range defs.Content {
+			if name := mappingValue(def, "name"); name != nil && usedTags[name.Value] {
+				kept = append(kept, def)
+			}
+		}
+		defs.Content = kept
+	}
+
+	if comps := mappingValue(root, "components"); comps != nil {
+		pruneComponents(root, comps)
+	}
+
+	bs, err = yaml.Marshal(&node)
+	if err != nil {
+		return nil, err
+	}
+
+	fd, err := NewDocumentFromBytes(bs)
+	if err != nil {
+		return nil, err
+	}
+
+	fd.PkgMap = slices.Clone(d.PkgMap)
+	fd.operationHooks = slices.Clone(d.operationHooks)
+	fd.schemaTransforms = slices.Clone(d.schemaTransforms)
+	fd.componentNameSep = d.componentNameSep
+
+	return fd, nil
+}
+
+// filterPathItems removes the operations of the path items that are not tagged
+// with one of the given tags and then removes the path items left without
+// operations. The tags of the operations kept are recorded in usedTags.
+func filterPathItems(pis *yaml.Node, tags []string, usedTags map[string]bool) {
+	keptItems := make([]*yaml.Node, 0, len(pis.Content))
+	for i := 0; i+1 < len(pis.Content); i += 2 {
+		pattern, pi := pis.Content[i], pis.Content[i+1]
+
+		hasOp := false
+		keptFields := make([]*yaml.Node, 0, len(pi.Content))
+		for j := 0; j+1 < len(pi.Content); j += 2 {
+			key, value := pi.Content[j], pi.Content[j+1]
+			if slices.Contains(operationKeys, key.Value) {
+				var opTags []string
+				if tagList := mappingValue(value, "tags"); tagList != nil {
+					for _, tag := range tagList.Content {
+						opTags = append(opTags, tag.Value)
+					}
+				}
+
+				if !slices.ContainsFunc(opTags, func(tag string) bool { return slices.Contains(tags, tag) }) {
+					continue
+				}
+
+				hasOp = true
+				for _, tag := range opTags {
+					usedTags[tag] = true
+				}
+			}
+
+			keptFields = append(keptFields, key, value)
+		}
+
+		if hasOp {
+			pi.Content = keptFields
+			keptItems = append(keptItems, pattern, pi)
+		}
+	}
+
+	pis.Content = keptItems
+}
+
+// pruneComponents removes every component that is not referenced from outside
+// the components, directly or through other components. Security schemes are
+// referenced by name rather than by $ref, so they are left alone.
+func pruneComponents(root, comps *yaml.Node) {
+	const prefix = "#/components/"
+
+	used := map[string]bool{}
+	var queue []string
+	use := func(ref string) {
+		if strings.HasPrefix(ref, prefix) && !used[ref] {
+			used[ref] = true
+			queue = append(queue, ref)
+		}
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "components" {
+			walkRefs(context.Background(), root.Content[i+1], use)
+		}
+	}
+
+	for len(queue) > 0 {
+		ref := queue[0]
+		queue = queue[1:]
+
+		section, name, _ := strings.Cut(strings.TrimPrefix(ref, prefix), "/")
+		if items := mappingValue(comps, section); items != nil {
+			if item := mappingValue(items, name); item != nil {
+				walkRefs(context.Background(), item, use)
+			}
+		}
+	}
+
+	keptSections := make([]*yaml.Node, 0, len(comps.Content))
+	for i := 0; i+1 < len(comps.Content); i += 2 {
+		section, items := comps.Content[i], comps.Content[i+1]
+		if section.Value != "securitySchemes" {
+			kept := make([]*yaml.Node, 0, len(items.Content))
+			for j := 0; j+1 < len(items.Content); j += 2 {
+				if used[prefix+section.Value+"/"+items.Content[j].Value] {
+					kept = append(kept, items.Content[j], items.Content[j+1])
+				}
+			}
+			items.Content = kept
+		}
+
+		if len(items.Content) > 0 {
+			keptSections = append(keptSections, section, items)
+		}
+	}
+	comps.Content = keptSections
+}
+
+// docKeys are the keys holding documentation only, which RenderCompact removes
+// when they are empty.
+var docKeys = map[string]bool{
+	"description": true,
+	"summary":     true,
+	"title":       true,
+	"tags":        true,
+}
+
+// valueKeys are the keys holding literal values rather than OpenAPI objects,
+// which RenderCompact and the OpenAPI 3.0 downgrade leave alone.
+var valueKeys = map[string]bool{
+	"const":    true,
+	"default":  true,
+	"enum":     true,
+	"example":  true,
+	"examples": true,
+}
+
+// compactNode removes empty documentation from the node tree.
+func compactNode(n *yaml.Node) {
+	switch n.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, c := range n.Content {
+			compactNode(c)
+		}
+	case yaml.MappingNode:
+		content := make([]*yaml.Node, 0, len(n.Content))
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			switch {
+			case valueKeys[key.Value] || strings.HasPrefix(key.Value, "x-"):
+			case docKeys[key.Value]:
+				if value.Kind == yaml.SequenceNode {
+					value.Content = slices.DeleteFunc(value.Content, isEmptyScalar)
+					if len(value.Content) == 0 {
+						continue
+					}
+				} else if isEmptyScalar(value) {
+					continue
+				}
+
+				compactNode(value)
+			default:
+				compactNode(value)
+			}
+
+			content = append(content, key, value)
+		}
+		n.Content = content
+	}
+}
+
+// isEmptyScalar returns true if the node is null or an empty string.
+func isEmptyScalar(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode &&
+		(n.Tag == "!!null" || (n.Tag == "!!str" && n.Value == ""))
+}
+
 func (d *Document) Title(title string) *Document {
 	d.DataModel.Model.Info.Title = title
 	return d
@@ -99,6 +611,212 @@ func (d *Document) Version(version string) *Document {
 	return d
 }
 
+// AddExtension adds a vendor extension to the top level of the document. The
+// name must begin with "x-".
+func (d *Document) AddExtension(name string, value *yaml.Node) *Document {
+	if err := setExtension(&d.DataModel.Model.Extensions, name, value); err != nil {
+		return withErr(d, err)
+	}
+
+	return d
+}
+
+// ExternalDocs links the document to additional documentation, such as a
+// hand-written guide.
+func (d *Document) ExternalDocs(url, description string) *Document {
+	d.DataModel.Model.ExternalDocs = &base.ExternalDoc{
+		URL:         url,
+		Description: description,
+	}
+	return d
+}
+
+// Tag provides DSL methods for describing a tag defined on the document.
+type Tag struct {
+	Tag *base.Tag
+}
+
+// ExternalDocs links the tag to additional documentation.
+func (t *Tag) ExternalDocs(url, description string) *Tag {
+	t.Tag.ExternalDocs = &base.ExternalDoc{
+		URL:         url,
+		Description: description,
+	}
+	return t
+}
+
+// Tag defines a tag with a description at the document level. Tags used by
+// operations need not be defined, but defining them adds a description to the
+// generated documentation and sets the order in which they are listed. If the
+// tag is already defined, its description is replaced.
+func (d *Document) Tag(name, description string, mods ...func(t *Tag)) *Document {
+	tag := d.tag(name)
+	tag.Description = description
+
+	t := &Tag{Tag: tag}
+	for _, mod := range mods {
+		mod(t)
+	}
+
+	return d
+}
+
+// tag returns the tag with the given name defined on the document, defining it
+// if it is not defined yet.
+func (d *Document) tag(name string) *base.Tag {
+	for _, t := range d.DataModel.Model.Tags {
+		if t.Name == name {
+			return t
+		}
+	}
+
+	tag := &base.Tag{Name: name}
+	d.DataModel.Model.Tags = append(d.DataModel.Model.Tags, tag)
+
+	return tag
+}
+
+// TagGroup groups the named tags under a heading using the x-tagGroups
+// extension, which is used by ReDoc to organize the navigation of large APIs.
+// If the group already exists, the tags are added to it.
+func (d *Document) TagGroup(groupName string, tags ...string) *Document {
+	var groups *yaml.Node
+	if d.DataModel.Model.Extensions != nil {
+		groups = d.DataModel.Model.Extensions.GetOrZero("x-tagGroups")
+	}
+
+	if groups == nil {
+		groups = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		d.AddExtension("x-tagGroups", groups)
+	}
+
+	var tagList *yaml.Node
+	for _, group := range groups.Content {
+		if name := mappingValue(group, "name"); name != nil && name.Value == groupName {
+			tagList = mappingValue(group, "tags")
+			break
+		}
+	}
+
+	if tagList == nil {
+		tagList = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		groups.Content = append(groups.Content, &yaml.Node{
+			Kind: yaml.MappingNode,
+			Tag:  "!!map",
+			Content: []*yaml.Node{
+				utils.CreateStringNode("name"), utils.CreateStringNode(groupName),
+				utils.CreateStringNode("tags"), tagList,
+			},
+		})
+	}
+
+	for _, tag := range tags {
+		tagList.Content = append(tagList.Content, utils.CreateStringNode(tag))
+	}
+
+	return d
+}
+
+// TagGroupWithDocs groups the named tags just like TagGroup and links each of
+// the tags to the given documentation URL. Tags that are not defined on the
+// document yet are defined without a description.
+func (d *Document) TagGroupWithDocs(groupName, docsURL string, tags ...string) *Document {
+	for _, name := range tags {
+		t := &Tag{Tag: d.tag(name)}
+		t.ExternalDocs(docsURL, "")
+	}
+
+	return d.TagGroup(groupName, tags...)
+}
+
+// mappingValue returns the value of the key in the mapping node or nil if the
+// key is not present.
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// Contact sets the contact information of the document.
+func (d *Document) Contact(name, url, email string) *Document {
+	d.DataModel.Model.Info.Contact = &base.Contact{
+		Name:  name,
+		URL:   url,
+		Email: email,
+	}
+	return d
+}
+
+// License sets the license of the document by name and URL.
+func (d *Document) License(name, url string) *Document {
+	d.DataModel.Model.Info.License = &base.License{
+		Name: name,
+		URL:  url,
+	}
+	return d
+}
+
+// LicenseIdentifier sets the license of the document by name and SPDX
+// identifier, such as "MIT". This is only supported by OpenAPI 3.1.
+func (d *Document) LicenseIdentifier(name, identifier string) *Document {
+	d.DataModel.Model.Info.License = &base.License{
+		Name:       name,
+		Identifier: identifier,
+	}
+	return d
+}
+
+// InfoSpec describes the info section of the document for use with SetInfo.
+type InfoSpec struct {
+	Title          string
+	Summary        string
+	Description    string
+	TermsOfService string
+	Version        string
+	Contact        *base.Contact
+	License        *base.License
+}
+
+// SetInfo sets all the fields of the info section of the document given in
+// the spec at once. Fields of the spec that are empty are left unchanged.
+func (d *Document) SetInfo(info InfoSpec) *Document {
+	i := d.DataModel.Model.Info
+
+	if info.Title != "" {
+		i.Title = info.Title
+	}
+
+	if info.Summary != "" {
+		i.Summary = info.Summary
+	}
+
+	if info.Description != "" {
+		i.Description = info.Description
+	}
+
+	if info.TermsOfService != "" {
+		i.TermsOfService = info.TermsOfService
+	}
+
+	if info.Version != "" {
+		i.Version = info.Version
+	}
+
+	if info.Contact != nil {
+		i.Contact = info.Contact
+	}
+
+	if info.License != nil {
+		i.License = info.License
+	}
+
+	return d
+}
+
 func (d *Document) PackageMap(pairs ...string) *Document {
 	if d.PkgMap == nil {
 		d.PkgMap = make([]PackageMap, 0, len(pairs)/2)
@@ -114,6 +832,28 @@ func (d *Document) PackageMap(pairs ...string) *Document {
 	return d
 }
 
+// ComponentNameSeparator sets the separator used in place of the slashes and
+// dots in the names of schema components added with SchemaComponentRef and
+// SchemaRefWith, and in the references to them. By default, the name of a Go
+// type is used as is, such as "github.com/zostay/arrest-go.Pet". With a
+// separator of "_", that becomes "github_com_zostay_arrest-go_Pet". The
+// package map is applied before the separator.
+func (d *Document) ComponentNameSeparator(sep string) *Document {
+	d.componentNameSep = sep
+	return d
+}
+
+// componentName returns the name of the schema component for the given Go
+// type name after applying the package map and the component name separator.
+func (d *Document) componentName(typName string) string {
+	name := MappedName(typName, d.PkgMap)
+	if d.componentNameSep == "" {
+		return name
+	}
+
+	return strings.NewReplacer("/", d.componentNameSep, ".", d.componentNameSep).Replace(name)
+}
+
 func (d *Document) pathItem(pattern string) *v3.PathItem {
 	if d.DataModel.Model.Paths == nil {
 		d.DataModel.Model.Paths = &v3.Paths{}
@@ -131,68 +871,114 @@ func (d *Document) pathItem(pattern string) *v3.PathItem {
 	return pis.GetOrZero(pattern)
 }
 
-// Get creates a new GET operation at the given pattern. The Operation is
-// returned to be manipulated further.
-func (d *Document) Get(pattern string) *Operation {
-	pi := d.pathItem(pattern)
+// OperationHook registers a hook that is called whenever a new operation is
+// created by Get, Post, Put, Delete, etc. This is useful for applying
+// conventions that apply to every operation, such as adding a common header
+// parameter.
+func (d *Document) OperationHook(hook func(method, pattern string, o *Operation)) *Document {
+	d.operationHooks = append(d.operationHooks, hook)
+	return d
+}
 
-	if pi.Get == nil {
-		pi.Get = &v3.Operation{}
+// operation wraps the operation stored in the given slot of a path item,
+// creating it and running the operation hooks if it does not exist yet.
+func (d *Document) operation(method, pattern string, slot **v3.Operation) *Operation {
+	isNew := *slot == nil
+	if isNew {
+		*slot = &v3.Operation{}
 	}
 
-	v3o := pi.Get
-
-	o := &Operation{Operation: v3o}
+	o := &Operation{Operation: *slot}
 	d.AddHandler(o)
+
+	if isNew {
+		for _, hook := range d.operationHooks {
+			hook(method, pattern, o)
+		}
+	}
+
 	return o
 }
 
-// Post creates a new POST operation at the given pattern. The Operation is
-// returned to be manipulated further.
-func (d *Document) Post(pattern string) *Operation {
-	pi := d.pathItem(pattern)
+// Webhook creates a new webhook with the given name, which documents a POST
+// request the API makes to the client when an event occurs. Webhooks are only
+// supported by OpenAPI 3.1. Operation hooks are not run for webhooks. The
+// Operation is returned to be manipulated further.
+func (d *Document) Webhook(name string) *Operation {
+	if d.DataModel.Model.Webhooks == nil {
+		d.DataModel.Model.Webhooks = orderedmap.New[string, *v3.PathItem]()
+	}
+
+	pi, hasWebhook := d.DataModel.Model.Webhooks.Get(name)
+	if !hasWebhook {
+		pi = &v3.PathItem{}
+		d.DataModel.Model.Webhooks.Set(name, pi)
+	}
 
 	if pi.Post == nil {
 		pi.Post = &v3.Operation{}
 	}
 
-	v3o := pi.Post
-
-	o := &Operation{Operation: v3o}
+	o := &Operation{Operation: pi.Post}
 	d.AddHandler(o)
+
 	return o
 }
 
-// Put creates a new PUT operation at the given pattern. The Operation is
+// Get creates a new GET operation at the given pattern. The Operation is
 // returned to be manipulated further.
-func (d *Document) Put(pattern string) *Operation {
+func (d *Document) Get(pattern string) *Operation {
 	pi := d.pathItem(pattern)
+	return d.operation(http.MethodGet, pattern, &pi.Get)
+}
 
-	if pi.Put == nil {
-		pi.Put = &v3.Operation{}
-	}
-
-	v3o := pi.Put
+// Post creates a new POST operation at the given pattern. The Operation is
+// returned to be manipulated further.
+func (d *Document) Post(pattern string) *Operation {
+	pi := d.pathItem(pattern)
+	return d.operation(http.MethodPost, pattern, &pi.Post)
+}
 
-	o := &Operation{Operation: v3o}
-	d.AddHandler(o)
-	return o
+// Put creates a new PUT operation at the given pattern. The Operation is
+// returned to be manipulated further.
+func (d *Document) Put(pattern string) *Operation {
+	pi := d.pathItem(pattern)
+	return d.operation(http.MethodPut, pattern, &pi.Put)
 }
 
 // Delete creates a new DELETE operation at the given pattern. The Operation is
 // returned to be manipulated further.
 func (d *Document) Delete(pattern string) *Operation {
 	pi := d.pathItem(pattern)
+	return d.operation(http.MethodDelete, pattern, &pi.Delete)
+}
 
-	if pi.Delete == nil {
-		pi.Delete = &v3.Operation{}
-	}
+// Patch creates a new PATCH operation at the given pattern. The Operation is
+// returned to be manipulated further.
+func (d *Document) Patch(pattern string) *Operation {
+	pi := d.pathItem(pattern)
+	return d.operation(http.MethodPatch, pattern, &pi.Patch)
+}
+
+// Options creates a new OPTIONS operation at the given pattern. The Operation is
+// returned to be manipulated further.
+func (d *Document) Options(pattern string) *Operation {
+	pi := d.pathItem(pattern)
+	return d.operation(http.MethodOptions, pattern, &pi.Options)
+}
 
-	v3o := pi.Delete
+// Head creates a new HEAD operation at the given pattern. The Operation is
+// returned to be manipulated further.
+func (d *Document) Head(pattern string) *Operation {
+	pi := d.pathItem(pattern)
+	return d.operation(http.MethodHead, pattern, &pi.Head)
+}
 
-	o := &Operation{Operation: v3o}
-	d.AddHandler(o)
-	return o
+// Trace creates a new TRACE operation at the given pattern. The Operation is
+// returned to be manipulated further.
+func (d *Document) Trace(pattern string) *Operation {
+	pi := d.pathItem(pattern)
+	return d.operation(http.MethodTrace, pattern, &pi.Trace)
 }
 
 // AddServer adds a new server URL to the document.
@@ -205,6 +991,43 @@ func (d *Document) AddServer(url string) *Document {
 	return d
 }
 
+// ServerVariable describes a variable used in a templated server URL.
+type ServerVariable struct {
+	// Default is the value to use when the client does not supply one.
+	Default string
+
+	// Enum lists the allowed values of the variable, if limited.
+	Enum []string
+
+	// Description describes the variable.
+	Description string
+}
+
+// AddServerWithVariables adds a new templated server URL to the document, such
+// as "https://{region}.api.example.com". The keys of vars name the variables
+// used in the URL. The variables are added in sorted order.
+func (d *Document) AddServerWithVariables(url string, vars map[string]ServerVariable) *Document {
+	if d.DataModel.Model.Servers == nil {
+		d.DataModel.Model.Servers = []*v3.Server{}
+	}
+
+	svars := orderedmap.New[string, *v3.ServerVariable]()
+	for _, name := range slices.Sorted(maps.Keys(vars)) {
+		v := vars[name]
+		svars.Set(name, &v3.ServerVariable{
+			Default:     v.Default,
+			Enum:        v.Enum,
+			Description: v.Description,
+		})
+	}
+
+	d.DataModel.Model.Servers = append(d.DataModel.Model.Servers, &v3.Server{
+		URL:       url,
+		Variables: svars,
+	})
+	return d
+}
+
 // AddSecurityRequirement configures the global security scopes. The key in
 // the map is the security scheme name and the value is the list of scopes.
 func (d *Document) AddSecurityRequirement(reqs map[string][]string) *Document {
@@ -220,28 +1043,44 @@ func (d *Document) AddSecurityRequirement(reqs map[string][]string) *Document {
 	return d
 }
 
-func remapSchemaRefs(ctx context.Context, sp *base.SchemaProxy, pkgMap []PackageMap) *base.SchemaProxy {
+func remapSchemaRefs(ctx context.Context, sp *base.SchemaProxy, mapName func(string) string) *base.SchemaProxy {
 	if sp.IsReference() {
 		if strings.HasPrefix(sp.GetReference(), "#/components/schemas/") {
 			return base.CreateSchemaProxyRef(
 				"#/components/schemas/" +
-					MappedName(
-						strings.TrimPrefix(sp.GetReference(), "#/components/schemas/"),
-						pkgMap,
-					))
+					mapName(strings.TrimPrefix(sp.GetReference(), "#/components/schemas/")))
+		}
+
+		return nil
+	}
+
+	for _, of := range [][]*base.SchemaProxy{sp.Schema().AllOf, sp.Schema().AnyOf, sp.Schema().OneOf} {
+		for i, osp := range of {
+			if newSp := remapSchemaRefs(ctx, osp, mapName); newSp != nil {
+				of[i] = newSp
+			}
 		}
-	} else if slices.Contains(sp.Schema().Type, "object") {
+	}
+
+	if slices.Contains(sp.Schema().Type, "object") {
 		for pair := range orderedmap.Iterate(context.TODO(), sp.Schema().Properties) {
 			vsp := pair.Value()
-			newSp := remapSchemaRefs(ctx, vsp, pkgMap)
+			newSp := remapSchemaRefs(ctx, vsp, mapName)
 			if newSp != nil {
 				sp.Schema().Properties.Set(pair.Key(), newSp)
 			}
 		}
 
+		if ap := sp.Schema().AdditionalProperties; ap != nil && ap.IsA() && ap.A != nil {
+			newSp := remapSchemaRefs(ctx, ap.A, mapName)
+			if newSp != nil {
+				ap.A = newSp
+			}
+		}
+
 		return nil
 	} else if slices.Contains(sp.Schema().Type, "array") && sp.Schema().Items.IsA() {
-		newSp := remapSchemaRefs(ctx, sp.Schema().Items.A, pkgMap)
+		newSp := remapSchemaRefs(ctx, sp.Schema().Items.A, mapName)
 		if newSp != nil {
 			sp.Schema().Items.A = newSp
 		}
@@ -269,15 +1108,98 @@ func (d *Document) SchemaComponent(fqn string, m *Model) *Document {
 
 	c.Schemas.Set(fqn, m.SchemaProxy)
 
+	// the child components refer to one another by their Go names, so they
+	// are remapped along with the model itself
 	for goPkg, sp := range m.ExtractChildRefs() {
-		childFqn := MappedName(goPkg, d.PkgMap)
+		childFqn := d.componentName(goPkg)
 		c.Schemas.Set(childFqn, sp)
+		if !sp.IsReference() {
+			remapSchemaRefs(context.TODO(), sp, d.componentName)
+		}
+	}
+
+	if !m.SchemaProxy.IsReference() {
+		remapSchemaRefs(context.TODO(), m.SchemaProxy, d.componentName)
+	}
+
+	return d
+}
+
+// ResponseComponent adds a response component to the document, which is
+// configured by the callback. Use ResponseRef to refer to it from an
+// operation, so that common responses, such as errors, are defined only once.
+func (d *Document) ResponseComponent(name string, cb func(r *Response)) *Document {
+	if d.DataModel.Model.Components == nil {
+		d.DataModel.Model.Components = &v3.Components{}
+	}
+
+	c := d.DataModel.Model.Components
+	if c.Responses == nil {
+		c.Responses = orderedmap.New[string, *v3.Response]()
+	}
+
+	res := &Response{Response: &v3.Response{}}
+	d.AddHandler(res)
+
+	cb(res)
+
+	if res.Response.Description == "" {
+		res.Response.Description = "Response"
+	}
+
+	c.Responses.Set(name, res.Response)
+
+	return d
+}
+
+// ParameterComponent adds a parameter component to the document, which is
+// configured by the callback. Use ParameterRefs to refer to it from an
+// operation, so that common parameters, such as pagination, are defined only
+// once.
+func (d *Document) ParameterComponent(name string, cb func(p *Parameter)) *Document {
+	if d.DataModel.Model.Components == nil {
+		d.DataModel.Model.Components = &v3.Components{}
+	}
+
+	c := d.DataModel.Model.Components
+	if c.Parameters == nil {
+		c.Parameters = orderedmap.New[string, *v3.Parameter]()
+	}
+
+	p := &Parameter{Parameter: &v3.Parameter{}}
+	d.AddHandler(p)
+
+	cb(p)
+
+	c.Parameters.Set(name, p.Parameter)
+
+	return d
+}
+
+// RequestBodyComponent adds a request body component to the document with the
+// given media type and model. Use Operation.RequestBodyRef to refer to it, so
+// that a payload accepted by several operations is defined only once.
+func (d *Document) RequestBodyComponent(name, mt string, m *Model) *Document {
+	if m.SchemaProxy == nil {
+		return withErr(d, fmt.Errorf("model must be initialized"))
 	}
 
-	if slices.Contains(m.SchemaProxy.Schema().Type, "object") {
-		remapSchemaRefs(context.TODO(), m.SchemaProxy, d.PkgMap)
+	d.AddHandler(m)
+
+	if d.DataModel.Model.Components == nil {
+		d.DataModel.Model.Components = &v3.Components{}
 	}
 
+	c := d.DataModel.Model.Components
+	if c.RequestBodies == nil {
+		c.RequestBodies = orderedmap.New[string, *v3.RequestBody]()
+	}
+
+	content := orderedmap.New[string, *v3.MediaType]()
+	content.Set(mt, &v3.MediaType{Schema: m.SchemaProxy})
+
+	c.RequestBodies.Set(name, &v3.RequestBody{Content: content})
+
 	return d
 }
 
@@ -294,12 +1216,70 @@ func (d *Document) SecuritySchemeComponent(fqn string, m *SecurityScheme) *Docum
 	}
 
 	c.SecuritySchemes.Set(fqn, m.SecurityScheme)
+	m.componentName = fqn
+
+	return d
+}
+
+// SecuritySchemesFrom registers a security scheme component for every field of
+// the given struct that describes a scheme in its openapi struct tag. The tag
+// name is used as the component name and the type, scheme, bearerFormat, in,
+// and name props configure the scheme:
+//
+//	type Auth struct {
+//		// Bearer is a JWT issued by the login service.
+//		Bearer string `openapi:"bearerAuth,type=http,scheme=bearer,bearerFormat=JWT"`
+//
+//		// Key is an API key issued to the client.
+//		Key string `openapi:"apiKey,type=apiKey,in=header,name=X-API-Key"`
+//	}
+//
+// Fields without a type prop are skipped. The field documentation is used as
+// the description of the scheme.
+func (d *Document) SecuritySchemesFrom(v any) *Document {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return withErr(d, fmt.Errorf("security schemes must be described by a struct, got %v", t))
+	}
+
+	_, fieldDocs, _ := GoDocForStruct(t)
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+
+		info := NewTagInfo(f.Tag)
+		props := info.Props()
+		if info.IsIgnored() || props["type"] == "" {
+			continue
+		}
+
+		name := f.Name
+		if info.HasName() {
+			name = info.Name()
+		}
+
+		s := SecuritySchemeForType(props["type"]).
+			Scheme(props["scheme"]).
+			BearerFormat(props["bearerFormat"]).
+			In(props["in"]).
+			Name(props["name"])
+
+		if fieldDocs != nil {
+			s.Description(fieldDocs[name])
+		}
+
+		d.SecuritySchemeComponent(name, s)
+	}
 
 	return d
 }
 
 func (d *Document) SchemaComponentRef(m *Model) *SchemaComponent {
-	fqn := m.MappedName(d.PkgMap)
+	fqn := d.componentName(m.Name)
 
 	d.SchemaComponent(fqn, m)
 
@@ -309,6 +1289,83 @@ func (d *Document) SchemaComponentRef(m *Model) *SchemaComponent {
 	}
 }
 
+// SchemaRefWith adds the model as a schema component, just like
+// SchemaComponentRef, and returns a reference to it that carries its own
+// description. This gives each use of a shared component its own context. In
+// OpenAPI 3.1, the description is rendered alongside the $ref. OpenAPI 3.0
+// ignores siblings of $ref, so there the reference is wrapped in an allOf
+// instead.
+func (d *Document) SchemaRefWith(m *Model, description string) *Model {
+	fqn := d.componentName(m.Name)
+	d.SchemaComponent(fqn, m)
+
+	ref := "#/components/schemas/" + fqn
+
+	schema := &base.Schema{Description: description}
+	if d.isOpenAPI30() {
+		schema.AllOf = []*base.SchemaProxy{base.CreateSchemaProxyRef(ref)}
+	} else {
+		// libopenapi renders a reference schema as a bare $ref, so the $ref is
+		// added through the extensions to render it next to the description
+		setSchemaKeyword(schema, "$ref", utils.CreateStringNode(ref))
+	}
+
+	return &Model{
+		Name:        fqn,
+		SchemaProxy: base.CreateSchemaProxy(schema),
+	}
+}
+
+// DiscriminatedUnion builds a polymorphic model in which every member shares
+// the fields of a common base model. The base is added as a schema component
+// and each member is added as a schema component that combines the base with
+// the member's own fields:
+//
+//	allOf:
+//	  - $ref: '#/components/schemas/Pet'
+//	  - <member>
+//
+// The returned model is a oneOf of the members with a discriminator on the
+// named property mapping each key of members to its component. The property
+// should be defined by the base, where it is also made required.
+func DiscriminatedUnion(doc *Document, baseModel *Model, members map[string]*Model, propertyName string) *Model {
+	baseRef := doc.SchemaComponentRef(baseModel).Ref()
+
+	u := &Model{
+		SchemaProxy: base.CreateSchemaProxy(&base.Schema{}),
+	}
+	u.AddHandler(baseModel)
+
+	mapping := make(map[string]string, len(members))
+	oneOf := make([]*base.SchemaProxy, 0, len(members))
+	for _, value := range slices.Sorted(maps.Keys(members)) {
+		member := members[value]
+		u.AddHandler(member)
+
+		fqn := doc.componentName(member.Name)
+		doc.SchemaComponent(fqn, &Model{
+			Name: member.Name,
+			SchemaProxy: base.CreateSchemaProxy(&base.Schema{
+				AllOf: []*base.SchemaProxy{baseRef.SchemaProxy, member.SchemaProxy},
+			}),
+			makeRefs: member.makeRefs,
+		})
+
+		if !member.SchemaProxy.IsReference() && slices.Contains(member.SchemaProxy.Schema().Type, "object") {
+			remapSchemaRefs(context.TODO(), member.SchemaProxy, doc.componentName)
+		}
+
+		ref := SchemaRef(fqn)
+		oneOf = append(oneOf, ref.SchemaProxy)
+		mapping[value] = ref.SchemaProxy.GetReference()
+	}
+
+	u.SchemaProxy.Schema().OneOf = oneOf
+	requireProperty(baseModel.SchemaProxy, propertyName)
+
+	return u.Discriminator(propertyName, mapping)
+}
+
 // SchemaComponents lists all the schema components in the document.
 func (d *Document) SchemaComponents(ctx context.Context) []*SchemaComponent {
 	if d.DataModel.Model.Components == nil {
@@ -335,6 +1392,29 @@ func (d *Document) SchemaComponents(ctx context.Context) []*SchemaComponent {
 	return scs
 }
 
+// FindSchemaComponent looks up the schema component with the given name. The
+// Model of the returned component shares its schema with the document, so
+// changes made to it, such as setting a description, are reflected when the
+// document is rendered. It returns false if there is no such component.
+func (d *Document) FindSchemaComponent(name string) (*SchemaComponent, bool) {
+	if d.DataModel.Model.Components == nil || d.DataModel.Model.Components.Schemas == nil {
+		return nil, false
+	}
+
+	sp, hasSchema := d.DataModel.Model.Components.Schemas.Get(name)
+	if !hasSchema {
+		return nil, false
+	}
+
+	return &SchemaComponent{
+		schema: &Model{
+			Name:        name,
+			SchemaProxy: sp,
+		},
+		ref: SchemaRef(name),
+	}, true
+}
+
 // Operations lists all the operations in the document.
 func (d *Document) Operations(ctx context.Context) []*Operation {
 	if d.DataModel.Model.Paths == nil {
@@ -377,3 +1457,65 @@ func (d *Document) Operations(ctx context.Context) []*Operation {
 
 	return os
 }
+
+var pathParamRegex = regexp.MustCompile(`\{([^}]+)\}`)
+
+// CheckPathParameters verifies that every parameter named in a path pattern,
+// such as the id in "/pets/{id}", is documented as a path parameter on every
+// operation of that path, either on the operation itself or on the path item.
+// References to parameter components, as made by ParameterRefs, count as the
+// parameter of the component. It returns an error for each parameter that is
+// missing, or nil if all are documented.
+func (d *Document) CheckPathParameters() []error {
+	if d.DataModel.Model.Paths == nil || d.DataModel.Model.Paths.PathItems == nil {
+		return nil
+	}
+
+	var errs []error
+	for pattern, pi := range d.DataModel.Model.Paths.PathItems.FromOldest() {
+		matches := pathParamRegex.FindAllStringSubmatch(pattern, -1)
+		if len(matches) == 0 {
+			continue
+		}
+
+		for method, op := range pi.GetOperations().FromOldest() {
+			documented := map[string]bool{}
+			for _, p := range slices.Concat(pi.Parameters, op.Parameters) {
+				if p = d.resolveParameter(p); p != nil && p.In == "path" {
+					documented[p.Name] = true
+				}
+			}
+
+			for _, match := range matches {
+				if !documented[match[1]] {
+					errs = append(errs, fmt.Errorf("%s %s: path parameter %q is not documented",
+						strings.ToUpper(method), pattern, match[1]))
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// resolveParameter returns the parameter component referred to by a parameter
+// made with ParameterRefs, or the parameter itself if it is not a reference.
+// It returns nil if the component does not exist.
+func (d *Document) resolveParameter(p *v3.Parameter) *v3.Parameter {
+	if p.Extensions == nil {
+		return p
+	}
+
+	ref := p.Extensions.GetOrZero("$ref")
+	if ref == nil {
+		return p
+	}
+
+	name, isParam := strings.CutPrefix(ref.Value, "#/components/parameters/")
+	comps := d.DataModel.Model.Components
+	if !isParam || comps == nil || comps.Parameters == nil {
+		return nil
+	}
+
+	return comps.Parameters.GetOrZero(name)
+}
@@ -2,9 +2,14 @@ package arrest_test
 
 import (
 	"context"
+	"encoding/json"
 	"reflect"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/zostay/arrest-go"
@@ -321,6 +326,9 @@ components:
                     type: string
                 message:
                     type: string
+            required:
+                - code
+                - message
             description: An error response.
         zostay.arrest.test.v1.ListConnectionsResponse:
             type: object
@@ -329,6 +337,8 @@ components:
                     type: array
                     items:
                         $ref: '#/components/schemas/zostay.arrest.test.v1.Connection'
+            required:
+                - connections
             description: The list of connection configurations.
         zostay.arrest.test.v1.Connection:
             type: object
@@ -353,35 +363,52 @@ components:
                         type: array
                         items:
                             type: string
+            required:
+                - id
+                - name
+                - description
+                - type
+                - properties
+                - secrets
         zostay.arrest.test.v1.CreateConnectionRequest:
             type: object
             properties:
                 connection:
                     $ref: '#/components/schemas/zostay.arrest.test.v1.Connection'
+            required:
+                - connection
             description: The request to create a new connection configuration.
         zostay.arrest.test.v1.CreateConnectionResponse:
             type: object
             properties:
                 connection:
                     $ref: '#/components/schemas/zostay.arrest.test.v1.Connection'
+            required:
+                - connection
             description: The response to creating a new connection configuration.
         zostay.arrest.test.v1.GetConnectionResponse:
             type: object
             properties:
                 connection:
                     $ref: '#/components/schemas/zostay.arrest.test.v1.Connection'
+            required:
+                - connection
             description: The response to getting a connection configuration.
         zostay.arrest.test.v1.UpdateConnectionRequest:
             type: object
             properties:
                 connection:
                     $ref: '#/components/schemas/zostay.arrest.test.v1.Connection'
+            required:
+                - connection
             description: The request to update a connection configuration.
         zostay.arrest.test.v1.UpdateConnectionResponse:
             type: object
             properties:
                 connection:
                     $ref: '#/components/schemas/zostay.arrest.test.v1.Connection'
+            required:
+                - connection
             description: The response to updating a connection configuration.
 `
 
@@ -400,3 +427,781 @@ func TestDocument(t *testing.T) {
 	assert.NotEmpty(t, rend)
 	assert.Equal(t, expect, string(rend))
 }
+
+func TestDocument_OperationHook(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	var seen []string
+	doc.OperationHook(func(method, pattern string, o *arrest.Operation) {
+		seen = append(seen, method+" "+pattern)
+		o.Parameters(arrest.NParameters(1).
+			P(0, func(p *arrest.Parameter) {
+				p.Name("X-Correlation-ID").In("header").
+					Model(arrest.ModelFrom[string]())
+			}))
+	})
+
+	doc.Get("/things")
+	doc.Post("/things")
+	doc.Delete("/things/{id}")
+
+	// fetching an existing operation does not run the hooks again
+	doc.Get("/things")
+
+	require.NoError(t, doc.Err())
+	assert.Equal(t, []string{"GET /things", "POST /things", "DELETE /things/{id}"}, seen)
+
+	ops := doc.Operations(context.Background())
+	require.Len(t, ops, 3)
+	for _, op := range ops {
+		require.Len(t, op.Operation.Parameters, 1)
+		assert.Equal(t, "X-Correlation-ID", op.Operation.Parameters[0].Name)
+		assert.Equal(t, "header", op.Operation.Parameters[0].In)
+	}
+}
+
+func TestDocument_RenderCompact(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Get("/things").
+		Tags("").
+		OperationID("listThings").
+		Response("200", func(r *arrest.Response) {
+			r.Content("application/json", arrest.ModelFrom[[]string]())
+		})
+
+	doc.Post("/things").
+		Tags("things", "").
+		RequestBody("application/json", arrest.ModelFrom[CompactThing]()).
+		Response("204", func(r *arrest.Response) {
+			r.Content("application/json", &arrest.Model{
+				SchemaProxy: base.CreateSchemaProxy(&base.Schema{}),
+			})
+		})
+
+	require.NoError(t, doc.Err())
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), "tags:")
+	assert.Contains(t, string(rend), `- ""`)
+
+	compact, err := doc.RenderCompact()
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(compact), "tags:"))
+	assert.Contains(t, string(compact), "- things")
+	assert.NotContains(t, string(compact), `- ""`)
+	assert.Contains(t, string(compact), "operationId: listThings")
+	assert.Less(t, len(compact), len(rend))
+
+	// schema values and empty schemas are kept
+	assert.Contains(t, string(compact), `default: ""`)
+	assert.Contains(t, string(compact), "schema: {}")
+}
+
+type CompactThing struct {
+	Nickname string `json:"nickname" openapi:",default=''"`
+}
+
+func TestDocument_FindSchemaComponent(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.SchemaComponent("Error", arrest.ModelFrom[ErrorPayload]())
+
+	_, found := doc.FindSchemaComponent("Missing")
+	assert.False(t, found)
+
+	sc, found := doc.FindSchemaComponent("Error")
+	require.True(t, found)
+	assert.Equal(t, "Error", sc.Schema().Name)
+
+	sc.Description("Describes what went wrong.")
+
+	rend, err := doc.OpenAPI.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), "description: Describes what went wrong.")
+}
+
+func TestDocument_References(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("")
+	require.NoError(t, err)
+
+	require.NoError(t, OpenAPI(doc))
+
+	refs := doc.References(context.Background())
+	assert.ElementsMatch(t, []string{
+		"#/components/schemas/zostay.arrest.test.v1.ListConnectionsResponse",
+		"#/components/schemas/zostay.arrest.test.v1.ErrorPayload",
+		"#/components/schemas/zostay.arrest.test.v1.CreateConnectionRequest",
+		"#/components/schemas/zostay.arrest.test.v1.CreateConnectionResponse",
+		"#/components/schemas/zostay.arrest.test.v1.GetConnectionResponse",
+		"#/components/schemas/zostay.arrest.test.v1.UpdateConnectionRequest",
+		"#/components/schemas/zostay.arrest.test.v1.UpdateConnectionResponse",
+		"#/components/schemas/zostay.arrest.test.v1.Connection",
+	}, refs)
+}
+
+func TestDocument_RenderJSON(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("")
+	require.NoError(t, err)
+
+	require.NoError(t, OpenAPI(doc))
+
+	rend, err := doc.RenderJSON()
+	require.NoError(t, err)
+
+	var out struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Title string `json:"title"`
+		} `json:"info"`
+		Paths map[string]any `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(rend, &out))
+	assert.Equal(t, "3.1.0", out.OpenAPI)
+	assert.Equal(t, "Connection Service", out.Info.Title)
+	assert.Contains(t, out.Paths, "/connections/{id}")
+}
+
+func TestDocument_SetInfo(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.SetInfo(arrest.InfoSpec{
+		Title:          "Pet Store",
+		Summary:        "Buy and sell pets.",
+		Description:    "The Pet Store API manages an inventory of pets.",
+		TermsOfService: "https://example.com/terms",
+		Version:        "1.2.3",
+		Contact: &base.Contact{
+			Name:  "Pet Store Support",
+			URL:   "https://example.com/support",
+			Email: "support@example.com",
+		},
+		License: &base.License{
+			Name:       "MIT",
+			Identifier: "MIT",
+		},
+	})
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+
+	for _, line := range []string{
+		"title: Pet Store",
+		"summary: Buy and sell pets.",
+		"description: The Pet Store API manages an inventory of pets.",
+		"termsOfService: https://example.com/terms",
+		"version: 1.2.3",
+		"name: Pet Store Support",
+		"url: https://example.com/support",
+		"email: support@example.com",
+		"name: MIT",
+		"identifier: MIT",
+	} {
+		assert.Contains(t, string(rend), line)
+	}
+
+	doc.SetInfo(arrest.InfoSpec{Version: "1.2.4"})
+	assert.Equal(t, "Pet Store", doc.DataModel.Model.Info.Title)
+	assert.Equal(t, "1.2.4", doc.DataModel.Model.Info.Version)
+}
+
+type LegacyWidget struct {
+	Name   *string `json:"name"`
+	Weight float64 `json:"weight" openapi:",exclusiveMinimum=0"`
+}
+
+func TestNewDocumentWithVersion(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocumentWithVersion("test", "3.0.3")
+	require.NoError(t, err)
+
+	doc.SchemaComponent("Widget", arrest.ModelFrom[LegacyWidget](arrest.WithNullablePointers()))
+	doc.SchemaComponent("Kennel", arrest.ModelFrom[Kennel](arrest.WithNullablePointers()))
+	require.NoError(t, doc.Err())
+
+	// the model itself is still built in the 3.1 style
+	widget, found := doc.FindSchemaComponent("Widget")
+	require.True(t, found)
+	name := widget.Schema().SchemaProxy.Schema().Properties.GetOrZero("name").Schema()
+	assert.Equal(t, []string{"string", "null"}, name.Type)
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), "openapi: 3.0.3")
+	assert.Contains(t, string(rend), "nullable: true")
+	assert.Contains(t, string(rend), "minimum: 0")
+	assert.Contains(t, string(rend), "exclusiveMinimum: true")
+	assert.NotContains(t, string(rend), `- "null"`)
+	assert.NotContains(t, string(rend), "- null")
+	assert.NotContains(t, string(rend), `type: "null"`)
+
+	js, err := doc.RenderJSON()
+	require.NoError(t, err)
+
+	var out struct {
+		OpenAPI    string `json:"openapi"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]map[string]any `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(js, &out))
+	assert.Equal(t, "3.0.3", out.OpenAPI)
+
+	props := out.Components.Schemas["Kennel"].Properties
+	assert.Len(t, props["previous"]["anyOf"], 1)
+	assert.Equal(t, true, props["previous"]["nullable"])
+
+	props = out.Components.Schemas["Widget"].Properties
+	assert.Equal(t, "string", props["name"]["type"])
+	assert.Equal(t, true, props["name"]["nullable"])
+	assert.Equal(t, true, props["weight"]["exclusiveMinimum"])
+	assert.Equal(t, float64(0), props["weight"]["minimum"])
+}
+
+func TestNewDocumentWithVersion_Examples(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocumentWithVersion("test", "3.0.3")
+	require.NoError(t, err)
+
+	// an example that happens to look like a 3.1 schema is left as written
+	doc.Get("/rules").
+		Response("200", func(r *arrest.Response) {
+			r.Description("The rule.").
+				Content("application/json", arrest.ModelFrom[map[string]string]())
+		}).
+		ResponseExample("200", "application/json", "rule", map[string]any{
+			"type":             []any{"a", nil},
+			"exclusiveMinimum": 3,
+		})
+	require.NoError(t, doc.Err())
+
+	js, err := doc.RenderJSON()
+	require.NoError(t, err)
+
+	var out struct {
+		Paths map[string]map[string]struct {
+			Responses map[string]struct {
+				Content map[string]struct {
+					Examples map[string]struct {
+						Value map[string]any `json:"value"`
+					} `json:"examples"`
+				} `json:"content"`
+			} `json:"responses"`
+		} `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(js, &out))
+
+	value := out.Paths["/rules"]["get"].Responses["200"].Content["application/json"].Examples["rule"].Value
+	assert.Equal(t, map[string]any{
+		"type":             []any{"a", nil},
+		"exclusiveMinimum": float64(3),
+	}, value)
+}
+
+func TestDocument_SchemaRefWith(t *testing.T) {
+	t.Parallel()
+
+	for _, version := range []string{"3.1.0", "3.0.3"} {
+		t.Run(version, func(t *testing.T) {
+			t.Parallel()
+
+			doc, err := arrest.NewDocumentWithVersion("test", version)
+			require.NoError(t, err)
+
+			doc.Get("/things").
+				Response("404", func(r *arrest.Response) {
+					r.Content("application/json",
+						doc.SchemaRefWith(arrest.ModelFrom[ErrorPayload](), "The thing was not found."))
+				})
+			require.NoError(t, doc.Err())
+
+			ref := "#/components/schemas/github.com/zostay/arrest-go_test.ErrorPayload"
+			assert.Equal(t, []string{ref}, doc.References(context.Background()))
+
+			rend, err := doc.Render()
+			require.NoError(t, err)
+			assert.Contains(t, string(rend), "description: The thing was not found.")
+
+			if version == "3.0.3" {
+				assert.Contains(t, string(rend), "allOf:")
+			} else {
+				assert.NotContains(t, string(rend), "allOf:")
+			}
+		})
+	}
+}
+
+func TestDocument_ContactAndLicense(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Contact("API Support", "https://example.com/support", "support@example.com").
+		License("Apache 2.0", "https://www.apache.org/licenses/LICENSE-2.0.html")
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), `    contact:
+        name: API Support
+        url: https://example.com/support
+        email: support@example.com
+    license:
+        name: Apache 2.0
+        url: https://www.apache.org/licenses/LICENSE-2.0.html
+`)
+
+	doc.LicenseIdentifier("MIT", "MIT")
+
+	rend, err = doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), `    license:
+        name: MIT
+        identifier: MIT
+`)
+}
+
+func TestDocument_ExternalDocs(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.ExternalDocs("https://example.com/guide", "The user guide.")
+	doc.Get("/things").
+		ExternalDocs("https://example.com/guide/things", "Working with things.")
+
+	require.NoError(t, doc.Err())
+
+	assert.Equal(t, "https://example.com/guide", doc.DataModel.Model.ExternalDocs.URL)
+
+	ops := doc.Operations(context.Background())
+	require.Len(t, ops, 1)
+	assert.Equal(t, "Working with things.", ops[0].Operation.ExternalDocs.Description)
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), `externalDocs:
+    description: The user guide.
+    url: https://example.com/guide
+`)
+	assert.Contains(t, string(rend), `            externalDocs:
+                description: Working with things.
+                url: https://example.com/guide/things
+`)
+}
+
+func TestDocument_Tag(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Tag("Things", "Operations on things.").
+		Tag("Widgets", "Operations on widgets.", func(t *arrest.Tag) {
+			t.ExternalDocs("https://example.com/widgets", "All about widgets.")
+		}).
+		Tag("Things", "Operations on all the things.")
+
+	tags := doc.DataModel.Model.Tags
+	require.Len(t, tags, 2)
+	assert.Equal(t, "Things", tags[0].Name)
+	assert.Equal(t, "Operations on all the things.", tags[0].Description)
+	assert.Equal(t, "Widgets", tags[1].Name)
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), `tags:
+    - name: Things
+      description: Operations on all the things.
+    - name: Widgets
+      description: Operations on widgets.
+      externalDocs:
+        description: All about widgets.
+        url: https://example.com/widgets
+`)
+}
+
+func TestDocument_Webhook(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Webhook("jobFinished").
+		Description("Sent when a job finishes.").
+		RequestBody("application/json", arrest.ModelFrom[ExampleJob]()).
+		Response("200", func(r *arrest.Response) {
+			r.Description("The webhook was received.")
+		})
+
+	require.NoError(t, doc.Err())
+	assert.Empty(t, doc.Operations(context.Background()))
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), `webhooks:
+    jobFinished:
+        post:
+            description: Sent when a job finishes.
+            requestBody:
+`)
+}
+
+func TestDocument_CheckPathParameters(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Get("/things/{id}").
+		Parameters(arrest.NParameters(1).
+			P(0, func(p *arrest.Parameter) {
+				p.Name("id").In("path").Required().
+					Model(arrest.ModelFrom[string]())
+			}))
+	doc.Delete("/things/{id}")
+	doc.Get("/things")
+
+	require.NoError(t, doc.Err())
+
+	errs := doc.CheckPathParameters()
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], `DELETE /things/{id}: path parameter "id" is not documented`)
+
+	// parameters documented on the path item apply to every operation
+	pi := doc.DataModel.Model.Paths.PathItems.GetOrZero("/things/{id}")
+	pi.Parameters = append(pi.Parameters, pi.Get.Parameters...)
+	assert.Empty(t, doc.CheckPathParameters())
+
+	// references to parameter components are resolved
+	doc.ParameterComponent("OwnerID", func(p *arrest.Parameter) {
+		p.Name("ownerId").In("path").Required().
+			Model(arrest.ModelFrom[string]())
+	})
+	doc.Get("/owners/{ownerId}").
+		Parameters(arrest.ParameterRefs("OwnerID"))
+	doc.Get("/owners/{ownerId}/pets/{petId}").
+		Parameters(arrest.ParameterRefs("OwnerID", "Missing"))
+
+	require.NoError(t, doc.Err())
+
+	errs = doc.CheckPathParameters()
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], `GET /owners/{ownerId}/pets/{petId}: path parameter "petId" is not documented`)
+}
+
+func TestDocument_AddServerWithVariables(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.AddServerWithVariables("https://{region}.api.example.com", map[string]arrest.ServerVariable{
+		"region": {
+			Default:     "us",
+			Enum:        []string{"us", "eu"},
+			Description: "The region the API is hosted in.",
+		},
+	})
+
+	require.NoError(t, doc.Err())
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), "- url: https://{region}.api.example.com")
+	assert.Contains(t, string(rend), "region:")
+	assert.Contains(t, string(rend), "default: us")
+	assert.Contains(t, string(rend), "- eu")
+}
+
+func TestDocument_ComponentNameSeparator(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.ComponentNameSeparator("_")
+
+	doc.Get("/connections").
+		Response("200", func(r *arrest.Response) {
+			r.Content("application/json",
+				doc.SchemaComponentRef(arrest.ModelFrom[ListConnectionsResponse]()).Ref())
+		})
+	require.NoError(t, doc.Err())
+
+	_, hasList := doc.FindSchemaComponent("github_com_zostay_arrest-go_test_ListConnectionsResponse")
+	assert.True(t, hasList)
+
+	_, hasConn := doc.FindSchemaComponent("github_com_zostay_arrest-go_test_Connection")
+	assert.True(t, hasConn)
+
+	assert.Equal(t, []string{
+		"#/components/schemas/github_com_zostay_arrest-go_test_ListConnectionsResponse",
+		"#/components/schemas/github_com_zostay_arrest-go_test_Connection",
+	}, doc.References(context.Background()))
+}
+
+type ChainOwner struct {
+	Pets []ChainPet `json:"pets" openapi:",elemRefName=ChainPet"`
+}
+
+type ChainPet struct {
+	Tag ChainTag `json:"tag" openapi:",refName=ChainTag"`
+}
+
+type ChainTag struct {
+	Name string `json:"name"`
+}
+
+func TestDocument_ComponentNameSeparator_Chain(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.ComponentNameSeparator("_")
+	doc.Get("/owner").
+		Response("200", func(r *arrest.Response) {
+			r.Content("application/json",
+				doc.SchemaComponentRef(arrest.ModelFrom[ChainOwner]()).Ref())
+		})
+	require.NoError(t, doc.Err())
+
+	refs := doc.References(context.Background())
+	assert.ElementsMatch(t, []string{
+		"#/components/schemas/github_com_zostay_arrest-go_test_ChainOwner",
+		"#/components/schemas/github_com_zostay_arrest-go_test_ChainPet",
+		"#/components/schemas/github_com_zostay_arrest-go_test_ChainTag",
+	}, refs)
+
+	for _, ref := range refs {
+		_, found := doc.FindSchemaComponent(strings.TrimPrefix(ref, "#/components/schemas/"))
+		assert.True(t, found, ref)
+	}
+}
+
+type UnionPet struct {
+	Kind string `json:"kind,omitempty"`
+	Name string `json:"name"`
+}
+
+type UnionCat struct {
+	Meows bool `json:"meows"`
+}
+
+type UnionDog struct {
+	Barks bool `json:"barks"`
+}
+
+func TestDiscriminatedUnion(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.PackageMap("test", "github.com/zostay/arrest-go_test")
+
+	pet := arrest.DiscriminatedUnion(doc, arrest.ModelFrom[UnionPet](), map[string]*arrest.Model{
+		"cat": arrest.ModelFrom[UnionCat](),
+		"dog": arrest.ModelFrom[UnionDog](),
+	}, "kind")
+	require.NoError(t, pet.Err())
+
+	schema := pet.SchemaProxy.Schema()
+	require.Len(t, schema.OneOf, 2)
+	assert.Equal(t, "#/components/schemas/test.UnionCat", schema.OneOf[0].GetReference())
+	assert.Equal(t, "#/components/schemas/test.UnionDog", schema.OneOf[1].GetReference())
+
+	require.NotNil(t, schema.Discriminator)
+	assert.Equal(t, "kind", schema.Discriminator.PropertyName)
+	assert.Equal(t, "#/components/schemas/test.UnionDog", schema.Discriminator.Mapping.GetOrZero("dog"))
+
+	for _, name := range []string{"test.UnionCat", "test.UnionDog"} {
+		member, hasMember := doc.FindSchemaComponent(name)
+		require.True(t, hasMember, name)
+
+		allOf := member.Schema().SchemaProxy.Schema().AllOf
+		require.Len(t, allOf, 2)
+		assert.Equal(t, "#/components/schemas/test.UnionPet", allOf[0].GetReference())
+	}
+
+	petBase, hasBase := doc.FindSchemaComponent("test.UnionPet")
+	require.True(t, hasBase)
+	assert.Contains(t, petBase.Schema().SchemaProxy.Schema().Required, "kind")
+}
+
+func TestDocument_AddExtension(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.AddExtension("x-api-id", utils.CreateStringNode("things-api"))
+
+	doc.Get("/things").
+		AddExtension("x-internal", utils.CreateBoolNode("true")).
+		Response("200", func(r *arrest.Response) {
+			r.Content("application/json",
+				arrest.ModelFrom[ErrorPayload]().
+					AddExtension("x-go-type", utils.CreateStringNode("ErrorPayload")))
+		})
+
+	require.NoError(t, doc.Err())
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), "x-api-id: things-api\n")
+	assert.Contains(t, string(rend), "x-internal: true")
+	assert.Contains(t, string(rend), "x-go-type: ErrorPayload")
+
+	doc.AddExtension("api-id", utils.CreateStringNode("things-api"))
+	assert.Error(t, doc.Err())
+}
+
+func TestDocument_PatchAndOptions(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Patch("/things/{id}").
+		OperationID("patchThing").
+		Response("204", func(r *arrest.Response) {})
+	doc.Options("/things/{id}").
+		OperationID("thingOptions").
+		Response("204", func(r *arrest.Response) {})
+	doc.Head("/things/{id}")
+	doc.Trace("/things/{id}")
+
+	require.NoError(t, doc.Err())
+	assert.Len(t, doc.Operations(context.Background()), 4)
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), `patch:
+            operationId: patchThing`)
+	assert.Contains(t, string(rend), `options:
+            operationId: thingOptions`)
+}
+
+func TestDocument_TagGroupWithDocs(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Tag("pets", "Everything about pets.").
+		TagGroupWithDocs("Store", "https://example.com/docs/store", "pets", "orders").
+		TagGroup("Store", "inventory")
+
+	require.NoError(t, doc.Err())
+
+	tags := doc.DataModel.Model.Tags
+	require.Len(t, tags, 2)
+	assert.Equal(t, "Everything about pets.", tags[0].Description)
+	for _, tag := range tags {
+		require.NotNil(t, tag.ExternalDocs, tag.Name)
+		assert.Equal(t, "https://example.com/docs/store", tag.ExternalDocs.URL)
+	}
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), "x-tagGroups:")
+	assert.Contains(t, string(rend), "- name: Store")
+	assert.Contains(t, string(rend), "- inventory")
+	assert.Contains(t, string(rend), "url: https://example.com/docs/store")
+}
+
+func TestDocument_SchemaTransform(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.PackageMap("test", "github.com/zostay/arrest-go_test")
+
+	var seen []string
+	doc.SchemaTransform(func(name string, s *base.Schema) {
+		seen = append(seen, name)
+		if slices.Contains(s.Type, "object") {
+			s.AdditionalProperties = &base.DynamicValue[*base.SchemaProxy, bool]{N: 1, B: false}
+		}
+	})
+
+	doc.Get("/connections").
+		Response("200", func(r *arrest.Response) {
+			r.Content("application/json",
+				doc.SchemaComponentRef(arrest.ModelFrom[ListConnectionsResponse]()).Ref())
+		})
+	require.NoError(t, doc.Err())
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test.ListConnectionsResponse", "test.Connection"}, seen)
+	assert.Equal(t, 2, strings.Count(string(rend), "additionalProperties: false"))
+}
+
+func TestDocument_FilterByTag(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.PackageMap("test", "github.com/zostay/arrest-go_test").
+		Tag("connections", "Manage connections.").
+		Tag("health", "Check the health of the service.")
+
+	doc.Get("/connections").
+		Tags("connections").
+		Response("200", func(r *arrest.Response) {
+			r.Content("application/json",
+				doc.SchemaComponentRef(arrest.ModelFrom[ListConnectionsResponse]()).Ref())
+		})
+	doc.Get("/health").
+		Tags("health").
+		Response("500", func(r *arrest.Response) {
+			r.Content("application/json",
+				doc.SchemaComponentRef(arrest.ModelFrom[ErrorPayload]()).Ref())
+		})
+	require.NoError(t, doc.Err())
+
+	filtered, err := doc.FilterByTag("connections")
+	require.NoError(t, err)
+
+	pis := filtered.DataModel.Model.Paths.PathItems
+	assert.Equal(t, []string{"/connections"}, slices.Collect(pis.KeysFromOldest()))
+
+	var names []string
+	for _, sc := range filtered.SchemaComponents(context.Background()) {
+		names = append(names, sc.Schema().Name)
+	}
+	assert.Equal(t, []string{"test.ListConnectionsResponse", "test.Connection"}, names)
+
+	require.Len(t, filtered.DataModel.Model.Tags, 1)
+	assert.Equal(t, "connections", filtered.DataModel.Model.Tags[0].Name)
+
+	// the original document is unchanged
+	assert.Equal(t, 2, doc.DataModel.Model.Paths.PathItems.Len())
+}
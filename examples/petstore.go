@@ -100,7 +100,7 @@ func main() {
 		panic(doc.Err())
 	}
 
-	rend, err := doc.OpenAPI.Render()
+	rend, err := doc.Render()
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,28 @@
+package arrest
+
+import "reflect"
+
+// UnregisterEnum removes the enum registered for the type, so that a test can
+// undo its registration when it is done.
+func UnregisterEnum(t reflect.Type) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	delete(enumRegistry, t)
+}
+
+// UnregisterScalarType removes the scalar type registered for the type.
+func UnregisterScalarType(t reflect.Type) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	delete(scalarRegistry, t)
+}
+
+// UnregisterTypeSchema removes the schema function registered for the type.
+func UnregisterTypeSchema(t reflect.Type) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	delete(schemaRegistry, t)
+}
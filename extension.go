@@ -0,0 +1,37 @@
+package arrest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// setExtension sets the named vendor extension in the given extensions map,
+// creating the map if needed. The name must begin with "x-".
+func setExtension(exts **orderedmap.Map[string, *yaml.Node], name string, value *yaml.Node) error {
+	if !strings.HasPrefix(name, "x-") {
+		return fmt.Errorf("extension name %q must begin with \"x-\"", name)
+	}
+
+	if *exts == nil {
+		*exts = orderedmap.New[string, *yaml.Node]()
+	}
+
+	(*exts).Set(name, value)
+
+	return nil
+}
+
+// setRefExtension sets the $ref of an object that libopenapi has no way to
+// build as a reference, such as a parameter, response, or request body. The
+// $ref is added through the extensions, which are rendered as is.
+func setRefExtension(exts **orderedmap.Map[string, *yaml.Node], ref string) {
+	if *exts == nil {
+		*exts = orderedmap.New[string, *yaml.Node]()
+	}
+
+	(*exts).Set("$ref", utils.CreateStringNode(ref))
+}
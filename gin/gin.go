@@ -1,8 +1,12 @@
 package gin
 
 import (
+	"context"
 	"net/http"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/zostay/arrest-go"
@@ -49,6 +53,15 @@ func (d *Document) Put(pattern string) *Operation {
 	}
 }
 
+func (d *Document) Patch(pattern string) *Operation {
+	return &Operation{
+		Operation: *d.Document.Patch(pattern),
+		method:    http.MethodPatch,
+		pattern:   pattern,
+		r:         d.r,
+	}
+}
+
 func (d *Document) Delete(pattern string) *Operation {
 	return &Operation{
 		Operation: *d.Document.Delete(pattern),
@@ -58,11 +71,119 @@ func (d *Document) Delete(pattern string) *Operation {
 	}
 }
 
+var routeParamRegex = regexp.MustCompile(`[:*]([^/]+)`)
+
+// FromRouter adds a stub operation to the document for every GET, POST, PUT,
+// and DELETE route registered with the given router. Each Gin path parameter,
+// such as :id or *filepath, becomes a required string path parameter of the
+// operation. Routes using other methods are skipped. This is intended for
+// scaffolding documentation for an existing API, so the stubs should be filled
+// in with responses and the rest afterward. Operations that are already in the
+// document are kept and path parameters they already document are not added
+// again.
+func (d *Document) FromRouter(r *gin.Engine) *Document {
+	for _, route := range r.Routes() {
+		pattern := routeParamRegex.ReplaceAllString(route.Path, "{$1}")
+
+		var o *arrest.Operation
+		switch route.Method {
+		case http.MethodGet:
+			o = d.Document.Get(pattern)
+		case http.MethodPost:
+			o = d.Document.Post(pattern)
+		case http.MethodPut:
+			o = d.Document.Put(pattern)
+		case http.MethodDelete:
+			o = d.Document.Delete(pattern)
+		default:
+			continue
+		}
+
+		documented := d.documentedPathParams(pattern, o)
+
+		var names []string
+		for _, name := range routeParamRegex.FindAllStringSubmatch(route.Path, -1) {
+			if !documented[name[1]] {
+				names = append(names, name[1])
+			}
+		}
+
+		if len(names) == 0 {
+			continue
+		}
+
+		ps := arrest.NParameters(len(names))
+		for i, name := range names {
+			ps.P(i, func(p *arrest.Parameter) {
+				p.Name(name).In("path").Required().
+					Model(arrest.ModelFrom[string]())
+			})
+		}
+
+		o.Parameters(ps)
+	}
+
+	return d
+}
+
+// documentedPathParams returns the names of the path parameters already
+// documented for the operation, either on the operation or on its path item.
+func (d *Document) documentedPathParams(pattern string, o *arrest.Operation) map[string]bool {
+	params := o.Operation.Parameters
+	if pi, hasPi := d.DataModel.Model.Paths.PathItems.Get(pattern); hasPi {
+		params = append(slices.Clone(pi.Parameters), params...)
+	}
+
+	documented := make(map[string]bool, len(params))
+	for _, p := range params {
+		if p.In == "path" {
+			documented[p.Name] = true
+		}
+	}
+
+	return documented
+}
+
+// WithRateLimitHeaders documents the X-RateLimit-Limit, X-RateLimit-Remaining,
+// and X-RateLimit-Reset headers on every successful (2xx) response of every
+// operation in the document. It only affects responses that have already been
+// defined, so call it after all the operations have been added.
+func (d *Document) WithRateLimitHeaders() *Document {
+	for _, op := range d.Operations(context.Background()) {
+		if op.Operation.Responses == nil || op.Operation.Responses.Codes == nil {
+			continue
+		}
+
+		for code, res := range op.Operation.Responses.Codes.FromOldest() {
+			if !strings.HasPrefix(code, "2") {
+				continue
+			}
+
+			r := &arrest.Response{Response: res}
+			r.Header("X-RateLimit-Limit", arrest.ModelFrom[int](), func(h *arrest.Header) {
+				h.Description("The maximum number of requests permitted in the current window.")
+			}).
+				Header("X-RateLimit-Remaining", arrest.ModelFrom[int](), func(h *arrest.Header) {
+					h.Description("The number of requests remaining in the current window.")
+				}).
+				Header("X-RateLimit-Reset", arrest.ModelFrom[int64](), func(h *arrest.Header) {
+					h.Description("The time at which the current window resets in UTC epoch seconds.")
+				})
+
+			d.AddHandler(r)
+		}
+	}
+
+	return d
+}
+
 type Operation struct {
 	arrest.Operation
 	method  string
 	pattern string
 	r       gin.IRoutes
+
+	authContextKey any
 }
 
 var paramRegex = regexp.MustCompile(`\{([^}]+)\}`)
@@ -85,11 +206,136 @@ func (o *Operation) patternString() string {
 	return pattern
 }
 
+// WithAuthContext causes the bearer token of each request to be stored in the
+// request context under the given key before the handler is called. The token
+// is taken from an Authorization header using the Bearer scheme, so the
+// handler may read it with c.Request.Context().Value(key) rather than parsing
+// the header itself. Requests without a bearer token are passed through
+// unchanged. Call this before Handler.
+func (o *Operation) WithAuthContext(key any) *Operation {
+	o.authContextKey = key
+	return o
+}
+
+// Handler registers the handler for the operation. If the operation documents
+// a maximum request size with MaxRequestBytes, the request body is limited to
+// that size before the handler is called, so reading a larger body fails. If
+// the operation documents a Sunset date, the Sunset header is set on every
+// response. If the operation is a PUT or PATCH documented with
+// WithOptimisticConcurrency, requests without an If-Match header are rejected
+// with 428 Precondition Required. Comparing the entity tag and responding with
+// 412 is left to the handler.
 func (o *Operation) Handler(handler gin.HandlerFunc) *Operation {
+	if limit, hasLimit := o.maxRequestBytes(); hasLimit {
+		next := handler
+		handler = func(c *gin.Context) {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+			next(c)
+		}
+	}
+
+	if sunset := o.sunset(); sunset != "" {
+		next := handler
+		handler = func(c *gin.Context) {
+			c.Header("Sunset", sunset)
+			next(c)
+		}
+	}
+
+	if o.authContextKey != nil {
+		next := handler
+		handler = func(c *gin.Context) {
+			if token, hasToken := bearerToken(c.GetHeader("Authorization")); hasToken {
+				ctx := context.WithValue(c.Request.Context(), o.authContextKey, token)
+				c.Request = c.Request.WithContext(ctx)
+			}
+			next(c)
+		}
+	}
+
+	if o.requiresIfMatch() {
+		next := handler
+		handler = func(c *gin.Context) {
+			if c.GetHeader("If-Match") == "" {
+				c.AbortWithStatus(http.StatusPreconditionRequired)
+				return
+			}
+			next(c)
+		}
+	}
+
 	o.r.Match([]string{o.method}, o.patternString(), handler)
 	return o
 }
 
+// bearerToken returns the token of an Authorization header value using the
+// Bearer scheme.
+func bearerToken(authorization string) (string, bool) {
+	scheme, token, hasToken := strings.Cut(authorization, " ")
+	if !hasToken || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// maxRequestBytes returns the request size limit documented on the operation.
+func (o *Operation) maxRequestBytes() (int64, bool) {
+	if o.Operation.Operation.Extensions == nil {
+		return 0, false
+	}
+
+	node, hasLimit := o.Operation.Operation.Extensions.Get("x-max-request-bytes")
+	if !hasLimit {
+		return 0, false
+	}
+
+	limit, err := strconv.ParseInt(node.Value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return limit, true
+}
+
+// sunset returns the date documented for the Sunset header of a deprecated
+// operation or an empty string if there is none.
+func (o *Operation) sunset() string {
+	op := o.Operation.Operation
+	if op.Deprecated == nil || !*op.Deprecated || op.Responses == nil || op.Responses.Codes == nil {
+		return ""
+	}
+
+	for _, res := range op.Responses.Codes.FromOldest() {
+		if res.Headers == nil {
+			continue
+		}
+
+		if hdr := res.Headers.GetOrZero("Sunset"); hdr != nil && hdr.Example != nil {
+			return hdr.Example.Value
+		}
+	}
+
+	return ""
+}
+
+// requiresIfMatch returns true if the operation modifies a resource and
+// documents a required If-Match header.
+func (o *Operation) requiresIfMatch() bool {
+	if o.method != http.MethodPut && o.method != http.MethodPatch {
+		return false
+	}
+
+	for _, p := range o.Operation.Operation.Parameters {
+		if p.In == "header" && strings.EqualFold(p.Name, "If-Match") && p.Required != nil && *p.Required {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (o *Operation) StaticFile(file string) *Operation {
 	o.r.StaticFile(o.patternString(), file)
 	return o
@@ -0,0 +1,298 @@
+package gin_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zostay/arrest-go"
+	arrestgin "github.com/zostay/arrest-go/gin"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+func noop(*gin.Context) {}
+
+func TestDocument_FromRouter(t *testing.T) {
+	t.Parallel()
+
+	r := gin.New()
+	r.GET("/pets", noop)
+	r.GET("/pets/:id", noop)
+	r.DELETE("/pets/:id", noop)
+	r.PATCH("/pets/:id", noop)
+	r.GET("/files/*filepath", noop)
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Get("/pets/{id}").
+		Summary("Show a pet").
+		Parameters(arrest.NParameters(1).
+			P(0, func(p *arrest.Parameter) {
+				p.Name("id").In("path").Required().
+					Description("The pet ID.").
+					Model(arrest.ModelFrom[string]())
+			}))
+
+	arrestgin.NewDocument(doc, r).FromRouter(r)
+	require.NoError(t, doc.Err())
+
+	pis := doc.DataModel.Model.Paths.PathItems
+	assert.Equal(t, 3, pis.Len())
+
+	pets := pis.GetOrZero("/pets")
+	require.NotNil(t, pets)
+	require.NotNil(t, pets.Get)
+	assert.Empty(t, pets.Get.Parameters)
+
+	pet := pis.GetOrZero("/pets/{id}")
+	require.NotNil(t, pet)
+	assert.Nil(t, pet.Patch)
+
+	// the existing operation keeps its parameter and gets no duplicate
+	require.NotNil(t, pet.Get)
+	assert.Equal(t, "Show a pet", pet.Get.Summary)
+	require.Len(t, pet.Get.Parameters, 1)
+	assert.Equal(t, "The pet ID.", pet.Get.Parameters[0].Description)
+
+	require.NotNil(t, pet.Delete)
+	require.Len(t, pet.Delete.Parameters, 1)
+	assert.Equal(t, "id", pet.Delete.Parameters[0].Name)
+	assert.Equal(t, "path", pet.Delete.Parameters[0].In)
+	require.NotNil(t, pet.Delete.Parameters[0].Required)
+	assert.True(t, *pet.Delete.Parameters[0].Required)
+
+	files := pis.GetOrZero("/files/{filepath}")
+	require.NotNil(t, files)
+	require.NotNil(t, files.Get)
+	require.Len(t, files.Get.Parameters, 1)
+	assert.Equal(t, "filepath", files.Get.Parameters[0].Name)
+
+	// running it again adds nothing
+	arrestgin.NewDocument(doc, r).FromRouter(r)
+	require.NoError(t, doc.Err())
+	assert.Len(t, pis.GetOrZero("/pets/{id}").Delete.Parameters, 1)
+	assert.Len(t, pis.GetOrZero("/files/{filepath}").Get.Parameters, 1)
+}
+
+func TestDocument_WithRateLimitHeaders(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	gdoc := arrestgin.NewDocument(doc, gin.New())
+	gdoc.Get("/pets").
+		Response("200", func(r *arrest.Response) {
+			r.Description("The pets.")
+		})
+	gdoc.Post("/pets").
+		Response("201", func(r *arrest.Response) {
+			r.Description("The pet was created.")
+		}).
+		Response("400", func(r *arrest.Response) {
+			r.Description("The pet is invalid.")
+		})
+	gdoc.Delete("/pets/{id}")
+
+	gdoc.WithRateLimitHeaders()
+	require.NoError(t, doc.Err())
+
+	headers := []string{"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"}
+
+	pets := doc.DataModel.Model.Paths.PathItems.GetOrZero("/pets")
+	for code, op := range map[string]*v3.Operation{"200": pets.Get, "201": pets.Post} {
+		hdrs := op.Responses.Codes.GetOrZero(code).Headers
+		require.NotNil(t, hdrs, code)
+		assert.Equal(t, headers, slices.Collect(hdrs.KeysFromOldest()), code)
+		assert.Equal(t, []string{"integer"}, hdrs.GetOrZero("X-RateLimit-Reset").Schema.Schema().Type, code)
+	}
+
+	assert.Nil(t, pets.Post.Responses.Codes.GetOrZero("400").Headers)
+	assert.Nil(t, doc.DataModel.Model.Paths.PathItems.GetOrZero("/pets/{id}").Delete.Responses)
+
+	rend, err := doc.OpenAPI.Render()
+	require.NoError(t, err)
+	for _, header := range headers {
+		assert.Equal(t, 2, strings.Count(string(rend), header+":"), header)
+	}
+}
+
+type tokenKey struct{}
+
+func TestOperation_WithAuthContext(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	r := gin.New()
+	arrestgin.NewDocument(doc, r).
+		Get("/me").
+		WithAuthContext(tokenKey{}).
+		Handler(func(c *gin.Context) {
+			token, hasToken := c.Request.Context().Value(tokenKey{}).(string)
+			if !hasToken {
+				c.String(http.StatusUnauthorized, "no token")
+				return
+			}
+			c.String(http.StatusOK, token)
+		})
+	require.NoError(t, doc.Err())
+
+	tests := []struct {
+		name          string
+		authorization string
+		code          int
+		body          string
+	}{
+		{"bearer", "Bearer secret", http.StatusOK, "secret"},
+		{"lowercase bearer", "bearer secret", http.StatusOK, "secret"},
+		{"missing", "", http.StatusUnauthorized, "no token"},
+		{"basic", "Basic dXNlcjpwYXNz", http.StatusUnauthorized, "no token"},
+		{"empty bearer", "Bearer ", http.StatusUnauthorized, "no token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.code, w.Code)
+			assert.Equal(t, tt.body, w.Body.String())
+		})
+	}
+}
+
+func TestOperation_Handler_MaxRequestBytes(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	r := gin.New()
+	o := arrestgin.NewDocument(doc, r).Post("/uploads")
+	o.MaxRequestBytes(8)
+	o.Handler(func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.Status(http.StatusRequestEntityTooLarge)
+			return
+		}
+		c.String(http.StatusOK, string(body))
+	})
+	require.NoError(t, doc.Err())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/uploads", strings.NewReader("small")))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "small", w.Body.String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/uploads", strings.NewReader("much too large")))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestOperation_Handler_RequiresIfMatch(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	r := gin.New()
+	gdoc := arrestgin.NewDocument(doc, r)
+
+	handler := func(c *gin.Context) {
+		c.String(http.StatusOK, c.Param("id"))
+	}
+
+	put := gdoc.Put("/pets/{id}")
+	put.WithOptimisticConcurrency()
+	put.Handler(handler)
+
+	patch := gdoc.Patch("/pets/{id}")
+	patch.WithOptimisticConcurrency()
+	patch.Handler(handler)
+
+	gdoc.Delete("/pets/{id}").Handler(handler)
+	require.NoError(t, doc.Err())
+
+	for _, method := range []string{http.MethodPut, http.MethodPatch} {
+		t.Run(method, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(method, "/pets/42", nil))
+			assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+			assert.Empty(t, w.Body.String())
+
+			req := httptest.NewRequest(method, "/pets/42", nil)
+			req.Header.Set("If-Match", `"v1"`)
+
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "42", w.Body.String())
+		})
+	}
+
+	// operations not documented with WithOptimisticConcurrency are unaffected
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/pets/42", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestOperation_Handler_Sunset(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	const sunset = "Sat, 31 Oct 2026 23:59:59 GMT"
+
+	r := gin.New()
+	gdoc := arrestgin.NewDocument(doc, r)
+
+	old := gdoc.Get("/v1/pets")
+	old.Response("200", func(r *arrest.Response) {
+		r.Description("The pets.")
+	}).
+		Sunset(sunset)
+	old.Handler(func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	gdoc.Get("/v2/pets").Handler(func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	require.NoError(t, doc.Err())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/pets", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, sunset, w.Header().Get("Sunset"))
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v2/pets", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Sunset"))
+}
@@ -3,8 +3,10 @@ package arrest
 import (
 	"fmt"
 	"go/ast"
-	"go/doc"
+	"go/token"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 
 	"golang.org/x/tools/go/packages"
@@ -27,12 +29,13 @@ func goDocForFields(spec ast.Spec) map[string]fieldDoc {
 
 				comment := ""
 				if field.Doc != nil {
-					comment = field.Doc.Text()
+					comment = strings.TrimSpace(field.Doc.Text())
 				}
 
+				// the tag in the syntax tree is still a quoted string literal
 				tag := ""
 				if field.Tag != nil {
-					tag = field.Tag.Value
+					tag, _ = strconv.Unquote(field.Tag.Value)
 				}
 
 				fieldName := field.Names[0].Name
@@ -63,60 +66,80 @@ func GoDocForStruct(t reflect.Type) (string, map[string]string, error) {
 		return "", nil, nil
 	}
 
+	// a type declared in an external test package is only found by loading the
+	// tests of the package it tests
+	pkgPath, tests := t.PkgPath(), false
+	if strings.HasSuffix(pkgPath, "_test") {
+		pkgPath, tests = strings.TrimSuffix(pkgPath, "_test"), true
+	}
+
 	pkgs, err := packages.Load(&packages.Config{
-		Mode: packages.NeedTypes | packages.NeedSyntax | packages.NeedFiles,
-	}, t.PkgPath())
+		Mode:  packages.NeedName | packages.NeedSyntax | packages.NeedFiles,
+		Tests: tests,
+	}, pkgPath)
 	if err != nil {
 		return "", nil, err
 	}
 
-	if len(pkgs) == 0 {
+	pkgIdx := slices.IndexFunc(pkgs, func(pkg *packages.Package) bool {
+		return pkg.PkgPath == t.PkgPath()
+	})
+	if pkgIdx < 0 {
 		return "", nil, nil
 	}
 
-	pkg := pkgs[0]
+	pkg := pkgs[pkgIdx]
 	if pkg.Fset == nil || pkg.Syntax == nil {
 		return "", nil, nil
 	}
 
-	docPkg, err := doc.NewFromFiles(pkg.Fset, pkg.Syntax, t.PkgPath())
-	if err != nil {
-		return "", nil, err
-	}
-
-	for _, docType := range docPkg.Types {
-		if docType.Name == t.Name() {
-			comment := docType.Doc
-
-			var fieldMap map[string]fieldDoc
-			if docType.Decl != nil && len(docType.Decl.Specs) > 0 {
-				spec := docType.Decl.Specs[0]
-				fieldMap = goDocForFields(spec)
+	// go/doc skips the _test.go files declaring the types of an external test
+	// package, so the declaration is found in the syntax directly
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			genDecl, isGenDecl := decl.(*ast.GenDecl)
+			if !isGenDecl || genDecl.Tok != token.TYPE {
+				continue
 			}
 
-			fields := map[string]string{}
-			for key, docField := range fieldMap {
-				openApiKey := key
+			for _, spec := range genDecl.Specs {
+				typeSpec, isTypeSpec := spec.(*ast.TypeSpec)
+				if !isTypeSpec || typeSpec.Name.Name != t.Name() {
+					continue
+				}
 
-				info := NewTagInfo(docField.Tag)
-				if info.HasName() {
-					openApiKey = info.Name()
+				docGroup := typeSpec.Doc
+				if docGroup == nil && len(genDecl.Specs) == 1 {
+					docGroup = genDecl.Doc
 				}
 
-				// Rewrite commend to use the openapi name rather than the go name
-				ps := strings.SplitN(docField.Comment, " ", 2)
-				newComment := docField.Comment
-				if len(ps) == 2 {
-					firstWord, theRest := ps[0], ps[1]
-					if firstWord == key {
-						newComment = strings.Join([]string{openApiKey, theRest}, " ")
+				comment := docGroup.Text()
+				fieldMap := goDocForFields(typeSpec)
+
+				fields := map[string]string{}
+				for key, docField := range fieldMap {
+					openApiKey := key
+
+					info := NewTagInfo(docField.Tag)
+					if info.HasName() {
+						openApiKey = info.Name()
 					}
+
+					// Rewrite commend to use the openapi name rather than the go name
+					ps := strings.SplitN(docField.Comment, " ", 2)
+					newComment := docField.Comment
+					if len(ps) == 2 {
+						firstWord, theRest := ps[0], ps[1]
+						if firstWord == key {
+							newComment = strings.Join([]string{openApiKey, theRest}, " ")
+						}
+					}
+
+					fields[openApiKey] = newComment
 				}
 
-				fields[openApiKey] = newComment
+				return comment, fields, nil
 			}
-
-			return comment, fields, nil
 		}
 	}
 
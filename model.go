@@ -1,27 +1,148 @@
 package arrest
 
 import (
+	"encoding"
 	"errors"
 	"fmt"
+	"maps"
 	"path"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pb33f/libopenapi/datamodel/high/base"
 	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
 )
 
 // ErrUnsupportedModelType is returned when the model type is not supported.
 var ErrUnsupportedModelType = errors.New("unsupported model type")
 
+// ErrMaxDepthExceeded is returned when a type is nested more deeply than the
+// maximum depth permitted while generating a model.
+var ErrMaxDepthExceeded = errors.New("maximum model depth exceeded")
+
+// DefaultMaxDepth is the maximum depth to which types may be nested when
+// generating a model unless set with WithMaxDepth. Every struct field, pointer,
+// slice element, and map value descends one level. This also stops runaway
+// generation for recursive types.
+var DefaultMaxDepth = 64
+
+// ModelOption customizes how a Model is generated from a Go type.
+type ModelOption func(*modelOptions)
+
+type modelOptions struct {
+	fieldNamingStrategy func(goName string) string
+	timeFormat          string
+	nullablePointers    bool
+	hoistInlineStructs  bool
+	durationAsInteger   bool
+	inline              bool
+	maxDepth            int
+	closed              bool
+}
+
+// WithFieldNamingStrategy sets the function used to name the property of any
+// struct field that has no name set in its json or openapi struct tag. The
+// function is given the Go name of the field.
+func WithFieldNamingStrategy(strategy func(goName string) string) ModelOption {
+	return func(o *modelOptions) {
+		o.fieldNamingStrategy = strategy
+	}
+}
+
+// WithTimeFormat sets the format of the string schema generated for time.Time
+// values, such as "date" or a custom format label. The default format is
+// "date-time".
+func WithTimeFormat(format string) ModelOption {
+	return func(o *modelOptions) {
+		o.timeFormat = format
+	}
+}
+
+// WithNullablePointers causes pointer types to be treated as nullable, adding
+// "null" to the type of the generated schema (the OpenAPI 3.1 style).
+func WithNullablePointers() ModelOption {
+	return func(o *modelOptions) {
+		o.nullablePointers = true
+	}
+}
+
+// WithHoistedInlineStructs causes fields typed with an inline struct, such as
+// struct{ Street string }, to be referenced as schema components rather than
+// rendered inline. Each component is named after the enclosing struct and the
+// Go name of the field, so the Address field of Person becomes PersonAddress.
+// An inline struct nested within one of these is named after it in turn, as in
+// PersonAddressGeo.
+func WithHoistedInlineStructs() ModelOption {
+	return func(o *modelOptions) {
+		o.hoistInlineStructs = true
+	}
+}
+
+// WithDurationAsInteger causes time.Duration values to be described as an
+// integer count of nanoseconds, which is how encoding/json encodes them. By
+// default, they are described as an ISO 8601 duration string for use with
+// types that marshal durations that way.
+func WithDurationAsInteger() ModelOption {
+	return func(o *modelOptions) {
+		o.durationAsInteger = true
+	}
+}
+
+// WithInline causes the whole model to be rendered inline. The refName and
+// elemRefName props of the openapi struct tag are ignored and inline structs
+// are not hoisted, so no child schema components are registered when the model
+// is added to a document.
+func WithInline() ModelOption {
+	return func(o *modelOptions) {
+		o.inline = true
+	}
+}
+
+// WithMaxDepth sets the maximum depth to which types may be nested when
+// generating the model. Exceeding it results in ErrMaxDepthExceeded.
+func WithMaxDepth(depth int) ModelOption {
+	return func(o *modelOptions) {
+		o.maxDepth = depth
+	}
+}
+
+// Closed causes every object schema generated for a struct in the model to set
+// additionalProperties to false, so that consumers may reject properties that
+// the struct does not define.
+func Closed() ModelOption {
+	return func(o *modelOptions) {
+		o.closed = true
+	}
+}
+
 type refMapper struct {
 	makeRefs map[string]*base.SchemaProxy
+	opts     modelOptions
+	depth    int
+
+	// fieldName and fieldType name the struct field whose schema is being
+	// generated after its enclosing named struct, so an inline struct can be
+	// hoisted under a name that is unique to where it is nested.
+	fieldName string
+	fieldType reflect.Type
 }
 
-func newRefMapper(prefix string) *refMapper {
-	return &refMapper{
+func newRefMapper(prefix string, opts []ModelOption) *refMapper {
+	m := &refMapper{
 		makeRefs: make(map[string]*base.SchemaProxy),
+		opts:     modelOptions{maxDepth: DefaultMaxDepth},
+	}
+
+	for _, opt := range opts {
+		opt(&m.opts)
 	}
+
+	return m
 }
 
 func makeName(refName string, t reflect.Type, defaultSuffix string) string {
@@ -50,6 +171,7 @@ type Model struct {
 	Name        string
 	SchemaProxy *base.SchemaProxy
 
+	goType   reflect.Type
 	makeRefs map[string]*base.SchemaProxy
 
 	ErrHelper
@@ -79,6 +201,142 @@ func (m *Model) Description(description string) *Model {
 	return m
 }
 
+// AddExtension adds a vendor extension to the schema of the model. The name
+// must begin with "x-".
+func (m *Model) AddExtension(name string, value *yaml.Node) *Model {
+	if err := setExtension(&m.SchemaProxy.Schema().Extensions, name, value); err != nil {
+		return withErr(m, err)
+	}
+
+	return m
+}
+
+// Enum sets the list of values allowed by the model.
+func (m *Model) Enum(values ...any) *Model {
+	nodes, err := valueNodes(values...)
+	if err != nil {
+		return withErr(m, err)
+	}
+
+	m.SchemaProxy.Schema().Enum = nodes
+	return m
+}
+
+// Const fixes the model to exactly the given value. This is useful for the
+// marker field of a discriminated union member. The const keyword is only
+// supported by OpenAPI 3.1.
+func (m *Model) Const(value any) *Model {
+	nodes, err := valueNodes(value)
+	if err != nil {
+		return withErr(m, err)
+	}
+
+	m.SchemaProxy.Schema().Const = nodes[0]
+	return m
+}
+
+// Contains sets the contains schema of an array model along with the minimum
+// and maximum number of elements that must match it. If max is zero or less,
+// no maxContains is set.
+func (m *Model) Contains(cm *Model, min, max int) *Model {
+	m.AddHandler(cm)
+
+	schema := m.SchemaProxy.Schema()
+	if !slices.Contains(schema.Type, "array") {
+		return withErr(m, fmt.Errorf("contains requires an array schema"))
+	}
+
+	minContains := int64(min)
+	schema.Contains = cm.SchemaProxy
+	schema.MinContains = &minContains
+
+	if max > 0 {
+		maxContains := int64(max)
+		schema.MaxContains = &maxContains
+	}
+
+	return m
+}
+
+// Discriminator sets the discriminator of a polymorphic model. The mapping is
+// optional and maps discriminator values to schema references. Because the
+// discriminator property must be present for the discriminator to work, the
+// property is also added to the required list of the model and of every inline
+// oneOf, anyOf, or allOf member that defines it. Referenced members are left
+// unchanged.
+func (m *Model) Discriminator(propertyName string, mapping map[string]string) *Model {
+	d := &base.Discriminator{PropertyName: propertyName}
+	if len(mapping) > 0 {
+		d.Mapping = orderedmap.New[string, string]()
+		for _, value := range slices.Sorted(maps.Keys(mapping)) {
+			d.Mapping.Set(value, mapping[value])
+		}
+	}
+
+	m.SchemaProxy.Schema().Discriminator = d
+	requireProperty(m.SchemaProxy, propertyName)
+
+	return m
+}
+
+// DependentRequired declares that when the named field is present, the other
+// named fields are required as well. This is the dependentRequired keyword of
+// JSON Schema, which is only supported by OpenAPI 3.1.
+func (m *Model) DependentRequired(field string, requires ...string) *Model {
+	schema := m.SchemaProxy.Schema()
+	if schema.Extensions == nil {
+		schema.Extensions = orderedmap.New[string, *yaml.Node]()
+	}
+
+	// libopenapi does not model dependentRequired, so it is rendered through
+	// the extensions, which are output as is.
+	deps, hasDeps := schema.Extensions.Get("dependentRequired")
+	if !hasDeps {
+		deps = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		schema.Extensions.Set("dependentRequired", deps)
+	}
+
+	var list *yaml.Node
+	for i := 0; i+1 < len(deps.Content); i += 2 {
+		if deps.Content[i].Value == field {
+			list = deps.Content[i+1]
+			break
+		}
+	}
+
+	if list == nil {
+		list = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		deps.Content = append(deps.Content, utils.CreateStringNode(field), list)
+	}
+
+	for _, name := range requires {
+		list.Content = append(list.Content, utils.CreateStringNode(name))
+	}
+
+	return m
+}
+
+// requireProperty adds the named property to the required list of the schema
+// and its inline polymorphic members wherever the property is defined.
+func requireProperty(sp *base.SchemaProxy, name string) {
+	if sp == nil || sp.IsReference() {
+		return
+	}
+
+	schema := sp.Schema()
+	if schema.Properties != nil {
+		if _, hasProp := schema.Properties.Get(name); hasProp && !slices.Contains(schema.Required, name) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	for _, members := range [][]*base.SchemaProxy{schema.OneOf, schema.AnyOf, schema.AllOf} {
+		for _, member := range members {
+			requireProperty(member, name)
+		}
+	}
+}
+
 func (m *Model) ExtractChildRefs() map[string]*base.SchemaProxy {
 	return m.makeRefs
 }
@@ -86,6 +344,16 @@ func (m *Model) ExtractChildRefs() map[string]*base.SchemaProxy {
 func makeSchemaProxyStruct(t reflect.Type, makeRefs *refMapper) (*base.SchemaProxy, error) {
 	doc, fieldDocs, _ := GoDocForStruct(t)
 
+	// an inline struct is named after the field that holds it
+	structName, structType := t.Name(), t
+	if structName == "" && makeRefs.fieldType != nil {
+		structName, structType = makeRefs.fieldName, makeRefs.fieldType
+	}
+
+	var (
+		errs     []error
+		required []string
+	)
 	fieldProps := orderedmap.New[string, *base.SchemaProxy]()
 	for i := range t.NumField() {
 		f := t.Field(i)
@@ -112,8 +380,15 @@ func makeSchemaProxyStruct(t reflect.Type, makeRefs *refMapper) (*base.SchemaPro
 			fDescription = fieldDocs[fName]
 		}
 
+		if !info.HasName() && makeRefs.opts.fieldNamingStrategy != nil {
+			fName = makeRefs.opts.fieldNamingStrategy(fName)
+		}
+
 		fReplaceType := info.ReplacementType()
 
+		outerName, outerType := makeRefs.fieldName, makeRefs.fieldType
+		makeRefs.fieldName, makeRefs.fieldType = structName+f.Name, structType
+
 		var fSchema *base.SchemaProxy
 		if fReplaceType != "" {
 			fSchema = base.CreateSchemaProxy(&base.Schema{
@@ -132,6 +407,10 @@ func makeSchemaProxyStruct(t reflect.Type, makeRefs *refMapper) (*base.SchemaPro
 				fieldProps.Set(k, v)
 			}
 
+			required = append(required, anonSchema.Schema().Required...)
+
+			makeRefs.fieldName, makeRefs.fieldType = outerName, outerType
+
 			continue
 		} else {
 			var err error
@@ -146,8 +425,9 @@ func makeSchemaProxyStruct(t reflect.Type, makeRefs *refMapper) (*base.SchemaPro
 				fSchema.Schema().Description = fDescription
 			}
 
-			if fType.Kind() == reflect.Slice || fType.Kind() == reflect.Array {
-				if elemRefName := info.ElemRefName(); elemRefName != "" {
+			switch fType.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+				if elemRefName := info.ElemRefName(); elemRefName != "" && !makeRefs.opts.inline {
 					fElemSchema, err := makeSchemaProxy(fType.Elem(), makeRefs)
 					if err != nil {
 						return base.CreateSchemaProxy(&base.Schema{
@@ -156,30 +436,59 @@ func makeSchemaProxyStruct(t reflect.Type, makeRefs *refMapper) (*base.SchemaPro
 					}
 
 					elemRef := makeRefs.makeRef(elemRefName, fType.Elem(), fElemSchema)
-					itemSchema := base.CreateSchemaProxyRef(elemRef)
-					fSchema = base.CreateSchemaProxy(&base.Schema{
-						Type:  []string{"array"},
-						Items: &base.DynamicValue[*base.SchemaProxy, bool]{N: 0, A: itemSchema},
-					})
+					elemSchema := &base.DynamicValue[*base.SchemaProxy, bool]{
+						N: 0,
+						A: base.CreateSchemaProxyRef(elemRef),
+					}
+
+					if fType.Kind() == reflect.Map {
+						fSchema = base.CreateSchemaProxy(&base.Schema{
+							Type:                 []string{"object"},
+							AdditionalProperties: elemSchema,
+						})
+					} else {
+						fSchema = base.CreateSchemaProxy(&base.Schema{
+							Type:  []string{"array"},
+							Items: elemSchema,
+						})
+					}
 				}
 			}
+		}
 
-			if refName := info.RefName(); refName != "" {
-				ref := makeRefs.makeRef(refName, fType, fSchema)
-				fSchema = base.CreateSchemaProxyRef(ref)
+		makeRefs.fieldName, makeRefs.fieldType = outerName, outerType
+
+		refName, refType := info.RefName(), fType
+		if refName == "" && makeRefs.opts.hoistInlineStructs && isInlineStruct(fType) {
+			refName, refType = structName+f.Name, structType
+		}
+
+		if refName != "" && fReplaceType == "" && !makeRefs.opts.inline {
+			// a nullable pointer is nullable where it is used, not in the
+			// component that other fields may share
+			nullable := fType.Kind() == reflect.Ptr && makeRefs.opts.nullablePointers
+			if nullable {
+				fSchema.Schema().Type = slices.DeleteFunc(fSchema.Schema().Type, func(typ string) bool {
+					return typ == "null"
+				})
 			}
+
+			ref := makeRefs.makeRef(refName, refType, fSchema)
+
+			var err error
+			fSchema, err = makeFieldRef(ref, fSchema.Schema(), info, nullable)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to apply openapi tag of field named %q: %w", f.Name, err))
+			}
+		} else if err := applyTagProps(fSchema.Schema(), info); err != nil {
+			errs = append(errs, fmt.Errorf("failed to apply openapi tag of field named %q: %w", f.Name, err))
 		}
 
-		// TODO This would be super cool to implement.
-		//schemaLow := fSchema.GoLow().Schema()
-		//for key, value := range info.Props() {
-		//	switch key {
-		//	case "content-type":
-		//		schemaLow.ContentMediaType = low.NodeReference[string]{Value: value}
-		//	case "content-encoding":
-		//		schemaLow.ContentEncoding = low.NodeReference[string]{Value: value}
-		//	}
-		//}
+		if isRequired, err := info.IsRequired(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to apply openapi tag of field named %q: %w", f.Name, err))
+		} else if isRequired {
+			required = append(required, fName)
+		}
 
 		fieldProps.Set(fName, fSchema)
 	}
@@ -188,12 +497,260 @@ func makeSchemaProxyStruct(t reflect.Type, makeRefs *refMapper) (*base.SchemaPro
 		Description: doc,
 		Type:        []string{"object"},
 		Properties:  fieldProps,
+		Required:    required,
 	}
 
-	return base.CreateSchemaProxy(schema), nil
+	if makeRefs.opts.closed {
+		schema.AdditionalProperties = &base.DynamicValue[*base.SchemaProxy, bool]{N: 1, B: false}
+	}
+
+	return base.CreateSchemaProxy(schema), errors.Join(errs...)
+}
+
+// makeFieldRef returns a reference to the component schema of a field. The
+// component may be shared with other fields, so the props of the openapi tag
+// of the field are applied alongside the reference instead: with allOf or,
+// when the field is nullable, with anyOf and a null schema.
+func makeFieldRef(ref string, schema *base.Schema, info *TagInfo, nullable bool) (*base.SchemaProxy, error) {
+	// the type is only copied so the default and example match the component
+	use := &base.Schema{Type: slices.Clone(schema.Type)}
+	err := applyTagProps(use, info)
+
+	nullable = nullable || slices.Contains(use.Type, "null")
+	use.Type = nil
+
+	refSchema := base.CreateSchemaProxyRef(ref)
+	switch {
+	case nullable:
+		use.AnyOf = []*base.SchemaProxy{
+			refSchema,
+			base.CreateSchemaProxy(&base.Schema{Type: []string{"null"}}),
+		}
+	case !reflect.DeepEqual(use, &base.Schema{}):
+		use.AllOf = []*base.SchemaProxy{refSchema}
+	default:
+		return refSchema, err
+	}
+
+	return base.CreateSchemaProxy(use), err
+}
+
+// isInlineStruct returns true if the type is a struct type without a name or a
+// pointer to one.
+func isInlineStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct && t.Name() == ""
+}
+
+// applyTagProps sets the schema constraints given in the openapi struct tag of
+// a field on the schema of that field.
+func applyTagProps(schema *base.Schema, info *TagInfo) error {
+	var errs []error
+
+	if title := info.Title(); title != "" {
+		schema.Title = title
+	}
+
+	if format := info.Format(); format != "" {
+		schema.Format = format
+	}
+
+	// libopenapi only models contentMediaType and contentEncoding in the low
+	// level schema, which is not rendered for schemas built in code, so these
+	// are rendered through the extensions instead
+	if contentType := info.ContentType(); contentType != "" {
+		setSchemaKeyword(schema, "contentMediaType", utils.CreateStringNode(contentType))
+	}
+
+	if contentEncoding := info.ContentEncoding(); contentEncoding != "" {
+		setSchemaKeyword(schema, "contentEncoding", utils.CreateStringNode(contentEncoding))
+	}
+
+	if pattern := info.Pattern(); pattern != "" {
+		schema.Pattern = pattern
+	}
+
+	// like a zero minimum, a zero length is dropped by libopenapi
+	if minLength, err := info.MinLength(); err != nil {
+		errs = append(errs, err)
+	} else if minLength != nil && *minLength == 0 {
+		setSchemaKeyword(schema, "minLength", utils.CreateIntNode("0"))
+	} else if minLength != nil {
+		schema.MinLength = minLength
+	}
+
+	if maxLength, err := info.MaxLength(); err != nil {
+		errs = append(errs, err)
+	} else if maxLength != nil && *maxLength == 0 {
+		setSchemaKeyword(schema, "maxLength", utils.CreateIntNode("0"))
+	} else if maxLength != nil {
+		schema.MaxLength = maxLength
+	}
+
+	if minimum, err := info.Minimum(); err != nil {
+		errs = append(errs, err)
+	} else if minimum != nil && *minimum == 0 {
+		// libopenapi drops a zero minimum when rendering, so it is rendered
+		// through the extensions instead
+		setSchemaKeyword(schema, "minimum", utils.CreateIntNode("0"))
+	} else if minimum != nil {
+		schema.Minimum = minimum
+	}
+
+	if maximum, err := info.Maximum(); err != nil {
+		errs = append(errs, err)
+	} else if maximum != nil && *maximum == 0 {
+		setSchemaKeyword(schema, "maximum", utils.CreateIntNode("0"))
+	} else if maximum != nil {
+		schema.Maximum = maximum
+	}
+
+	// OpenAPI 3.1 exclusive bounds are numbers rather than booleans
+	if exclusiveMinimum, err := info.ExclusiveMinimum(); err != nil {
+		errs = append(errs, err)
+	} else if exclusiveMinimum != nil {
+		schema.ExclusiveMinimum = &base.DynamicValue[bool, float64]{N: 1, B: *exclusiveMinimum}
+	}
+
+	if exclusiveMaximum, err := info.ExclusiveMaximum(); err != nil {
+		errs = append(errs, err)
+	} else if exclusiveMaximum != nil {
+		schema.ExclusiveMaximum = &base.DynamicValue[bool, float64]{N: 1, B: *exclusiveMaximum}
+	}
+
+	if multipleOf, err := info.MultipleOf(); err != nil {
+		errs = append(errs, err)
+	} else if multipleOf != nil {
+		schema.MultipleOf = multipleOf
+	}
+
+	if minItems, err := info.MinItems(); err != nil {
+		errs = append(errs, err)
+	} else if minItems != nil && *minItems == 0 {
+		setSchemaKeyword(schema, "minItems", utils.CreateIntNode("0"))
+	} else if minItems != nil {
+		schema.MinItems = minItems
+	}
+
+	if maxItems, err := info.MaxItems(); err != nil {
+		errs = append(errs, err)
+	} else if maxItems != nil && *maxItems == 0 {
+		setSchemaKeyword(schema, "maxItems", utils.CreateIntNode("0"))
+	} else if maxItems != nil {
+		schema.MaxItems = maxItems
+	}
+
+	if uniqueItems, err := info.UniqueItems(); err != nil {
+		errs = append(errs, err)
+	} else if uniqueItems != nil {
+		schema.UniqueItems = uniqueItems
+	}
+
+	if minProperties, err := info.MinProperties(); err != nil {
+		errs = append(errs, err)
+	} else if minProperties != nil {
+		schema.MinProperties = minProperties
+	}
+
+	if maxProperties, err := info.MaxProperties(); err != nil {
+		errs = append(errs, err)
+	} else if maxProperties != nil {
+		schema.MaxProperties = maxProperties
+	}
+
+	if nullable, err := info.Nullable(); err != nil {
+		errs = append(errs, err)
+	} else if nullable != nil && *nullable {
+		makeNullable(schema)
+	}
+
+	if readOnly, err := info.ReadOnly(); err != nil {
+		errs = append(errs, err)
+	} else if readOnly != nil {
+		schema.ReadOnly = readOnly
+	}
+
+	if writeOnly, err := info.WriteOnly(); err != nil {
+		errs = append(errs, err)
+	} else if writeOnly != nil {
+		schema.WriteOnly = writeOnly
+	}
+
+	if value, hasDefault := info.Default(); hasDefault {
+		if node, err := scalarNode(schema, value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid default: %w", err))
+		} else {
+			schema.Default = node
+		}
+	}
+
+	if value, hasExample := info.Example(); hasExample {
+		if node, err := scalarNode(schema, value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid example: %w", err))
+		} else {
+			schema.Examples = append(schema.Examples, node)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// setSchemaKeyword sets a JSON Schema keyword that libopenapi does not model
+// by adding it to the extensions of the schema, which are rendered as is.
+func setSchemaKeyword(schema *base.Schema, keyword string, value *yaml.Node) {
+	if schema.Extensions == nil {
+		schema.Extensions = orderedmap.New[string, *yaml.Node]()
+	}
+
+	schema.Extensions.Set(keyword, value)
+}
+
+// makeNullable adds "null" to the types of the schema.
+func makeNullable(schema *base.Schema) {
+	if !slices.Contains(schema.Type, "null") {
+		schema.Type = append(schema.Type, "null")
+	}
+}
+
+// scalarNode converts a value given as a string in a struct tag into a scalar
+// YAML node matching the type of the schema.
+func scalarNode(schema *base.Schema, value string) (*yaml.Node, error) {
+	switch {
+	case slices.Contains(schema.Type, "integer"):
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return utils.CreateIntNode(strconv.FormatInt(i, 10)), nil
+	case slices.Contains(schema.Type, "number"):
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, err
+		}
+		return utils.CreateFloatNode(strconv.FormatFloat(f, 'g', -1, 64)), nil
+	case slices.Contains(schema.Type, "boolean"):
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, err
+		}
+		return utils.CreateBoolNode(strconv.FormatBool(b)), nil
+	default:
+		return utils.CreateStringNode(value), nil
+	}
 }
 
 func makeSchemaProxySlice(t reflect.Type, makeRefs *refMapper) (*base.SchemaProxy, error) {
+	// encoding/json encodes byte slices, but not byte arrays, as base64 strings
+	if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+		return base.CreateSchemaProxy(&base.Schema{
+			Type:   []string{"string"},
+			Format: "byte",
+		}), nil
+	}
+
 	sp, err := makeSchemaProxy(t.Elem(), makeRefs)
 	if err != nil {
 		return base.CreateSchemaProxy(&base.Schema{
@@ -230,16 +787,107 @@ func makeSchemaProxyMap(t reflect.Type, makeRefs *refMapper) (*base.SchemaProxy,
 		},
 	})
 
+	// encoding/json permits string keys, integer keys (encoded as strings), and
+	// keys that marshal to text
+	key := t.Key()
+	switch {
+	case key.Kind() == reflect.String, isTextMarshaler(key):
+	case key.Kind() >= reflect.Int && key.Kind() <= reflect.Int64:
+		schema.Schema().PropertyNames = base.CreateSchemaProxy(&base.Schema{
+			Type:    []string{"string"},
+			Pattern: "^-?[0-9]+$",
+		})
+	case key.Kind() >= reflect.Uint && key.Kind() <= reflect.Uintptr:
+		schema.Schema().PropertyNames = base.CreateSchemaProxy(&base.Schema{
+			Type:    []string{"string"},
+			Pattern: "^[0-9]+$",
+		})
+	default:
+		return schema, fmt.Errorf("%w: map key type %s cannot be encoded as a JSON object key", ErrUnsupportedModelType, key)
+	}
+
 	return schema, nil
 }
 
 func makeSchemaProxy(t reflect.Type, makeRefs *refMapper) (*base.SchemaProxy, error) {
+	makeRefs.depth++
+	defer func() { makeRefs.depth-- }()
+
+	if makeRefs.depth > makeRefs.opts.maxDepth {
+		return base.CreateSchemaProxy(&base.Schema{
+			Type: []string{"any"},
+		}), fmt.Errorf("%w: %d levels generating %s", ErrMaxDepthExceeded, makeRefs.opts.maxDepth, t)
+	}
+
+	if schema, hasSchema := registeredTypeSchema(t); hasSchema {
+		return base.CreateSchemaProxy(schema), nil
+	}
+
+	sp, err := makeSchemaProxyForKind(t, makeRefs)
+	if err != nil {
+		return sp, err
+	}
+
+	if enum, isEnum := registeredEnum(t); isEnum {
+		sp.Schema().Enum = enum
+	}
+
+	return sp, nil
+}
+
+var (
+	durationType      = reflect.TypeOf(time.Duration(0))
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// isTextMarshaler returns true if the type or a pointer to it implements
+// encoding.TextMarshaler.
+func isTextMarshaler(t reflect.Type) bool {
+	return t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)
+}
+
+func makeSchemaProxyForKind(t reflect.Type, makeRefs *refMapper) (*base.SchemaProxy, error) {
+	if t == durationType {
+		if makeRefs.opts.durationAsInteger {
+			return base.CreateSchemaProxy(&base.Schema{
+				Type:   []string{"integer"},
+				Format: "int64",
+			}), nil
+		}
+
+		return base.CreateSchemaProxy(&base.Schema{
+			Type:   []string{"string"},
+			Format: "duration",
+		}), nil
+	}
+
+	if scalar, isScalar := registeredScalarType(t); isScalar {
+		return base.CreateSchemaProxy(&base.Schema{
+			Type:   []string{scalar.typ},
+			Format: scalar.format,
+		}), nil
+	}
+
+	// encoding/json encodes text marshalers as strings; time.Time is one, but
+	// it is handled below to get the format right
+	if t.Kind() != reflect.Ptr && t != timeType && isTextMarshaler(t) {
+		return base.CreateSchemaProxy(&base.Schema{
+			Type: []string{"string"},
+		}), nil
+	}
+
 	switch t.Kind() {
 	case reflect.Struct:
 		if t.Name() == "Time" && t.PkgPath() == "time" {
+			format := "date-time"
+			if makeRefs.opts.timeFormat != "" {
+				format = makeRefs.opts.timeFormat
+			}
+
 			return base.CreateSchemaProxy(&base.Schema{
 				Type:   []string{"string"},
-				Format: "date-time",
+				Format: format,
 			}), nil
 		}
 		return makeSchemaProxyStruct(t, makeRefs)
@@ -248,7 +896,11 @@ func makeSchemaProxy(t reflect.Type, makeRefs *refMapper) (*base.SchemaProxy, er
 	case reflect.Map:
 		return makeSchemaProxyMap(t, makeRefs)
 	case reflect.Ptr:
-		return makeSchemaProxy(t.Elem(), makeRefs)
+		sp, err := makeSchemaProxy(t.Elem(), makeRefs)
+		if err == nil && makeRefs.opts.nullablePointers {
+			makeNullable(sp.Schema())
+		}
+		return sp, err
 	case reflect.Bool:
 		return base.CreateSchemaProxy(&base.Schema{
 			Type: []string{"boolean"},
@@ -289,11 +941,11 @@ func makeSchemaProxy(t reflect.Type, makeRefs *refMapper) (*base.SchemaProxy, er
 }
 
 // ModelFromReflect creates a new Model from a reflect.Type.
-func ModelFromReflect(t reflect.Type) *Model {
-	mr := newRefMapper(t.PkgPath())
+func ModelFromReflect(t reflect.Type, opts ...ModelOption) *Model {
+	mr := newRefMapper(t.PkgPath(), opts)
 	sp, err := makeSchemaProxy(t, mr)
 	name := strings.Join([]string{t.PkgPath(), t.Name()}, ".")
-	m := withErr(&Model{Name: name, SchemaProxy: sp, makeRefs: mr.makeRefs}, err)
+	m := withErr(&Model{Name: name, SchemaProxy: sp, goType: t, makeRefs: mr.makeRefs}, err)
 	if m.SchemaProxy == nil {
 		panic("nope")
 	} else if m.SchemaProxy.Schema() == nil {
@@ -303,9 +955,49 @@ func ModelFromReflect(t reflect.Type) *Model {
 }
 
 // ModelFrom creates a new Model from a type.
-func ModelFrom[T any]() *Model {
+func ModelFrom[T any](opts ...ModelOption) *Model {
 	var t T
-	return ModelFromReflect(reflect.TypeOf(t))
+	return ModelFromReflect(reflect.TypeOf(t), opts...)
+}
+
+// EnumFromValues creates a new Model for the type of the given values with an
+// enum listing those values. The values are encoded as JSON, just as they are sent over the
+// wire. If the type implements encoding.TextMarshaler, on either the value or
+// the pointer, the schema is a string and the enum lists the text of each
+// value:
+//
+//	arrest.EnumFromValues(ConnectionTypeA, ConnectionTypeB)
+func EnumFromValues[T any](values ...T) *Model {
+	m := ModelFrom[T]()
+	if m.Err() != nil {
+		return m
+	}
+
+	enum := make([]*yaml.Node, 0, len(values))
+	isText := false
+	for _, value := range values {
+		// the pointer has both the value and pointer methods, so encoding it
+		// finds a MarshalText or MarshalJSON with either receiver
+		if _, isTextMarshaler := any(&value).(encoding.TextMarshaler); isTextMarshaler {
+			isText = true
+		}
+
+		node, err := jsonValueNode(&value)
+		if err != nil {
+			return withErr(m, fmt.Errorf("failed to marshal enum value %v: %w", value, err))
+		}
+
+		enum = append(enum, node)
+	}
+
+	schema := m.SchemaProxy.Schema()
+	if isText {
+		schema.Type = []string{"string"}
+		schema.Format = ""
+	}
+
+	schema.Enum = enum
+	return m
 }
 
 func SchemaRef(fqn string) *Model {
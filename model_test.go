@@ -0,0 +1,1026 @@
+package arrest_test
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zostay/arrest-go"
+)
+
+func renderModel(t *testing.T, m *arrest.Model) string {
+	t.Helper()
+
+	require.NoError(t, m.Err())
+
+	rend, err := m.SchemaProxy.Render()
+	require.NoError(t, err)
+
+	return string(rend)
+}
+
+type AccountStatus string
+
+type Account struct {
+	Status   AccountStatus  `json:"status"`
+	Previous *AccountStatus `json:"previous"`
+}
+
+// the registry is shared by every test, so TestRegisterEnum registers a type
+// that no other test uses
+type SubscriptionStatus string
+
+const (
+	SubscriptionStatusActive   SubscriptionStatus = "active"
+	SubscriptionStatusInactive SubscriptionStatus = "inactive"
+)
+
+type Subscription struct {
+	Status   SubscriptionStatus  `json:"status"`
+	Previous *SubscriptionStatus `json:"previous"`
+}
+
+func TestRegisterEnum(t *testing.T) {
+	t.Parallel()
+
+	statusType := reflect.TypeOf(SubscriptionStatusActive)
+	err := arrest.RegisterEnum(statusType,
+		SubscriptionStatusActive, SubscriptionStatusInactive)
+	require.NoError(t, err)
+	t.Cleanup(func() { arrest.UnregisterEnum(statusType) })
+
+	m := arrest.ModelFrom[Subscription]()
+	require.NoError(t, m.Err())
+
+	props := m.SchemaProxy.Schema().Properties
+	for _, name := range []string{"status", "previous"} {
+		enum := props.GetOrZero(name).Schema().Enum
+		require.Len(t, enum, 2, name)
+		assert.Equal(t, "active", enum[0].Value, name)
+		assert.Equal(t, "inactive", enum[1].Value, name)
+	}
+
+	assert.Contains(t, renderModel(t, m), "enum:")
+}
+
+func TestModel_Contains(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[[]string]().
+		Contains(arrest.ModelFrom[string](), 1, 3)
+
+	rend := renderModel(t, m)
+	assert.Contains(t, rend, "contains:")
+	assert.Contains(t, rend, "minContains: 1")
+	assert.Contains(t, rend, "maxContains: 3")
+
+	m = arrest.ModelFrom[string]().
+		Contains(arrest.ModelFrom[string](), 1, 0)
+	assert.Error(t, m.Err())
+}
+
+type DiscriminatedCat struct {
+	Kind  string `json:"kind,omitempty"`
+	Meows bool   `json:"meows,omitempty"`
+}
+
+type DiscriminatedDog struct {
+	Kind  string `json:"kind,omitempty"`
+	Barks bool   `json:"barks,omitempty"`
+}
+
+func TestModel_Discriminator(t *testing.T) {
+	t.Parallel()
+
+	cat := arrest.ModelFrom[DiscriminatedCat]()
+	dog := arrest.ModelFrom[DiscriminatedDog]()
+
+	pet := &arrest.Model{
+		Name: "Pet",
+		SchemaProxy: base.CreateSchemaProxy(&base.Schema{
+			OneOf: []*base.SchemaProxy{cat.SchemaProxy, dog.SchemaProxy},
+		}),
+	}
+
+	pet.Discriminator("kind", map[string]string{
+		"dog": "#/components/schemas/Dog",
+		"cat": "#/components/schemas/Cat",
+	})
+
+	assert.Equal(t, []string{"kind"}, cat.SchemaProxy.Schema().Required)
+	assert.Equal(t, []string{"kind"}, dog.SchemaProxy.Schema().Required)
+
+	rend := renderModel(t, pet)
+	assert.Contains(t, rend, "propertyName: kind")
+	assert.Contains(t, rend, "- kind")
+}
+
+type LengthConstrained struct {
+	Name string `json:"name" openapi:",minLength=3,maxLength=64"`
+}
+
+type ZeroLengthConstrained struct {
+	Note  string `json:"note" openapi:",minLength=0"`
+	Blank string `json:"blank" openapi:",maxLength=0"`
+}
+
+type BadLengthConstrained struct {
+	Name string `json:"name" openapi:",minLength=three"`
+}
+
+func TestModelFrom_LengthConstraints(t *testing.T) {
+	t.Parallel()
+
+	rend := renderModel(t, arrest.ModelFrom[LengthConstrained]())
+	assert.Contains(t, rend, "minLength: 3")
+	assert.Contains(t, rend, "maxLength: 64")
+
+	rend = renderModel(t, arrest.ModelFrom[ZeroLengthConstrained]())
+	assert.Contains(t, rend, "minLength: 0\n")
+	assert.Contains(t, rend, "maxLength: 0\n")
+
+	assert.Error(t, arrest.ModelFrom[BadLengthConstrained]().Err())
+}
+
+type UntaggedProfile struct {
+	DisplayName string
+	HomePage    string
+}
+
+func snakeCase(goName string) string {
+	var b strings.Builder
+	for i, r := range goName {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteRune('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func TestModelFrom_FieldNamingStrategy(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[UntaggedProfile](arrest.WithFieldNamingStrategy(snakeCase))
+	require.NoError(t, m.Err())
+
+	props := m.SchemaProxy.Schema().Properties
+	assert.Equal(t, []string{"display_name", "home_page"}, slices.Collect(props.KeysFromOldest()))
+
+	m = arrest.ModelFrom[UntaggedProfile]()
+	require.NoError(t, m.Err())
+
+	props = m.SchemaProxy.Schema().Properties
+	assert.Equal(t, []string{"DisplayName", "HomePage"}, slices.Collect(props.KeysFromOldest()))
+}
+
+type RangeConstrained struct {
+	Percent int32   `json:"percent" openapi:",minimum=0,maximum=100"`
+	Ratio   float64 `json:"ratio" openapi:",minimum=0.5"`
+}
+
+type BadRangeConstrained struct {
+	Percent int32 `json:"percent" openapi:",maximum=lots"`
+}
+
+func TestModelFrom_RangeConstraints(t *testing.T) {
+	t.Parallel()
+
+	rend := renderModel(t, arrest.ModelFrom[RangeConstrained]())
+	assert.Contains(t, rend, "minimum: 0\n")
+	assert.Contains(t, rend, "maximum: 100")
+	assert.Contains(t, rend, "minimum: 0.5")
+
+	assert.Error(t, arrest.ModelFrom[BadRangeConstrained]().Err())
+}
+
+type ExclusiveRangeConstrained struct {
+	Price float64 `json:"price" openapi:",exclusiveMinimum=0,exclusiveMaximum=1000"`
+}
+
+type BadExclusiveRangeConstrained struct {
+	Price float64 `json:"price" openapi:",exclusiveMinimum=free"`
+}
+
+func TestModelFrom_ExclusiveRangeConstraints(t *testing.T) {
+	t.Parallel()
+
+	rend := renderModel(t, arrest.ModelFrom[ExclusiveRangeConstrained]())
+	assert.Contains(t, rend, "exclusiveMinimum: 0\n")
+	assert.Contains(t, rend, "exclusiveMaximum: 1000")
+
+	assert.Error(t, arrest.ModelFrom[BadExclusiveRangeConstrained]().Err())
+}
+
+type MultipleOfConstrained struct {
+	Amount float64 `json:"amount" openapi:",multipleOf=0.01"`
+	Count  int32   `json:"count" openapi:",multipleOf=5"`
+}
+
+type ZeroMultipleOfConstrained struct {
+	Amount float64 `json:"amount" openapi:",multipleOf=0"`
+}
+
+type NegativeMultipleOfConstrained struct {
+	Amount float64 `json:"amount" openapi:",multipleOf=-2"`
+}
+
+func TestModelFrom_MultipleOf(t *testing.T) {
+	t.Parallel()
+
+	rend := renderModel(t, arrest.ModelFrom[MultipleOfConstrained]())
+	assert.Contains(t, rend, "multipleOf: 0.01")
+	assert.Contains(t, rend, "multipleOf: 5")
+
+	assert.Error(t, arrest.ModelFrom[ZeroMultipleOfConstrained]().Err())
+	assert.Error(t, arrest.ModelFrom[NegativeMultipleOfConstrained]().Err())
+}
+
+type ItemLabels []string
+
+type ItemsConstrained struct {
+	Tags    []string   `json:"tags" openapi:",minItems=1,maxItems=10,uniqueItems"`
+	Labels  ItemLabels `json:"labels" openapi:",minItems=2"`
+	Members []Account  `json:"members" openapi:",elemRefName=Member,maxItems=5"`
+}
+
+type ZeroItemsConstrained struct {
+	Tags    []string `json:"tags" openapi:",minItems=0"`
+	Removed []string `json:"removed" openapi:",maxItems=0"`
+}
+
+type BadItemsConstrained struct {
+	Tags []string `json:"tags" openapi:",uniqueItems=sure"`
+}
+
+func TestModelFrom_ItemsConstraints(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[ItemsConstrained]()
+	require.NoError(t, m.Err())
+
+	props := m.SchemaProxy.Schema().Properties
+
+	tags := props.GetOrZero("tags").Schema()
+	require.NotNil(t, tags.MinItems)
+	require.NotNil(t, tags.MaxItems)
+	require.NotNil(t, tags.UniqueItems)
+	assert.Equal(t, int64(1), *tags.MinItems)
+	assert.Equal(t, int64(10), *tags.MaxItems)
+	assert.True(t, *tags.UniqueItems)
+
+	labels := props.GetOrZero("labels").Schema()
+	require.NotNil(t, labels.MinItems)
+	assert.Equal(t, int64(2), *labels.MinItems)
+
+	members := props.GetOrZero("members").Schema()
+	require.NotNil(t, members.MaxItems)
+	assert.Equal(t, int64(5), *members.MaxItems)
+
+	rend := renderModel(t, m)
+	assert.Contains(t, rend, "minItems: 1")
+	assert.Contains(t, rend, "maxItems: 10")
+	assert.Contains(t, rend, "uniqueItems: true")
+
+	rend = renderModel(t, arrest.ModelFrom[ZeroItemsConstrained]())
+	assert.Contains(t, rend, "minItems: 0\n")
+	assert.Contains(t, rend, "maxItems: 0\n")
+
+	assert.Error(t, arrest.ModelFrom[BadItemsConstrained]().Err())
+}
+
+type PropertiesConstrained struct {
+	Settings map[string]string  `json:"settings" openapi:",minProperties=1,maxProperties=20"`
+	Owners   map[string]Account `json:"owners" openapi:",elemRefName=Owner,maxProperties=3"`
+}
+
+func TestModelFrom_PropertiesConstraints(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[PropertiesConstrained]()
+	require.NoError(t, m.Err())
+
+	props := m.SchemaProxy.Schema().Properties
+
+	settings := props.GetOrZero("settings").Schema()
+	require.NotNil(t, settings.MinProperties)
+	require.NotNil(t, settings.MaxProperties)
+	assert.Equal(t, int64(1), *settings.MinProperties)
+	assert.Equal(t, int64(20), *settings.MaxProperties)
+
+	owners := props.GetOrZero("owners").Schema()
+	require.NotNil(t, owners.MaxProperties)
+	assert.Equal(t, int64(3), *owners.MaxProperties)
+	assert.True(t, owners.AdditionalProperties.A.IsReference())
+
+	rend := renderModel(t, m)
+	assert.Contains(t, rend, "minProperties: 1")
+	assert.Contains(t, rend, "maxProperties: 20")
+	assert.Contains(t, rend, "maxProperties: 3")
+}
+
+type PatternConstrained struct {
+	Slug    string `json:"slug" openapi:",pattern=^[a-z]+$"`
+	Country string `json:"country" openapi:",pattern='^[A-Z]{2,3}$',minLength=2"`
+}
+
+func TestModelFrom_Pattern(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[PatternConstrained]()
+	require.NoError(t, m.Err())
+
+	props := m.SchemaProxy.Schema().Properties
+	assert.Equal(t, "^[a-z]+$", props.GetOrZero("slug").Schema().Pattern)
+
+	country := props.GetOrZero("country").Schema()
+	assert.Equal(t, "^[A-Z]{2,3}$", country.Pattern)
+	require.NotNil(t, country.MinLength)
+	assert.Equal(t, int64(2), *country.MinLength)
+
+	assert.Contains(t, renderModel(t, m), "pattern:")
+}
+
+type Order struct {
+	Billing        string `json:"billing,omitempty"`
+	BillingAddress string `json:"billingAddress,omitempty"`
+	BillingCity    string `json:"billingCity,omitempty"`
+}
+
+func TestModel_DependentRequired(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[Order]().
+		DependentRequired("billing", "billingAddress").
+		DependentRequired("billing", "billingCity")
+
+	rend := renderModel(t, m)
+	assert.Contains(t, rend, "dependentRequired:")
+	assert.Contains(t, rend, "billing:")
+	assert.Contains(t, rend, "- billingAddress")
+	assert.Contains(t, rend, "- billingCity")
+}
+
+type Defaulted struct {
+	Limit   int     `json:"limit" openapi:",default=10"`
+	Ratio   float64 `json:"ratio" openapi:",default=0.5"`
+	Sort    string  `json:"sort" openapi:",default=name"`
+	Reverse bool    `json:"reverse" openapi:",default=false"`
+}
+
+type BadDefaulted struct {
+	Limit int `json:"limit" openapi:",default=ten"`
+}
+
+func TestModelFrom_Default(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[Defaulted]()
+	require.NoError(t, m.Err())
+
+	props := m.SchemaProxy.Schema().Properties
+	assert.Equal(t, "!!int", props.GetOrZero("limit").Schema().Default.Tag)
+	assert.Equal(t, "!!float", props.GetOrZero("ratio").Schema().Default.Tag)
+	assert.Equal(t, "!!str", props.GetOrZero("sort").Schema().Default.Tag)
+	assert.Equal(t, "!!bool", props.GetOrZero("reverse").Schema().Default.Tag)
+
+	rend := renderModel(t, m)
+	assert.Contains(t, rend, "default: 10")
+	assert.Contains(t, rend, "default: 0.5")
+	assert.Contains(t, rend, "default: name")
+	assert.Contains(t, rend, "default: false")
+
+	assert.Error(t, arrest.ModelFrom[BadDefaulted]().Err())
+}
+
+type ExamplePet struct {
+	Name string `json:"name" openapi:",example=Fluffy"`
+	Age  int    `json:"age" openapi:",example=3"`
+}
+
+func TestModelFrom_Example(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[ExamplePet]()
+	require.NoError(t, m.Err())
+
+	props := m.SchemaProxy.Schema().Properties
+	require.Len(t, props.GetOrZero("name").Schema().Examples, 1)
+	assert.Equal(t, "Fluffy", props.GetOrZero("name").Schema().Examples[0].Value)
+	require.Len(t, props.GetOrZero("age").Schema().Examples, 1)
+	assert.Equal(t, "!!int", props.GetOrZero("age").Schema().Examples[0].Tag)
+
+	rend := renderModel(t, m)
+	assert.Contains(t, rend, "examples:")
+	assert.Contains(t, rend, "- Fluffy")
+}
+
+type Appointment struct {
+	Day time.Time `json:"day"`
+}
+
+func TestModelFrom_TimeFormat(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[Appointment]()
+	require.NoError(t, m.Err())
+	assert.Equal(t, "date-time", m.SchemaProxy.Schema().Properties.GetOrZero("day").Schema().Format)
+
+	m = arrest.ModelFrom[Appointment](arrest.WithTimeFormat("date"))
+	require.NoError(t, m.Err())
+	assert.Equal(t, "date", m.SchemaProxy.Schema().Properties.GetOrZero("day").Schema().Format)
+
+	m = arrest.ModelFrom[Appointment](arrest.WithTimeFormat("unix-time"))
+	require.NoError(t, m.Err())
+	assert.Contains(t, renderModel(t, m), "format: unix-time")
+}
+
+type Contact struct {
+	Email string `json:"email" openapi:",format=email"`
+	ID    string `json:"id" openapi:",format=uuid"`
+	Name  string `json:"name"`
+}
+
+func TestModelFrom_Format(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[Contact]()
+	require.NoError(t, m.Err())
+
+	props := m.SchemaProxy.Schema().Properties
+	assert.Equal(t, []string{"string"}, props.GetOrZero("email").Schema().Type)
+	assert.Equal(t, "email", props.GetOrZero("email").Schema().Format)
+	assert.Equal(t, "uuid", props.GetOrZero("id").Schema().Format)
+	assert.Empty(t, props.GetOrZero("name").Schema().Format)
+
+	assert.Contains(t, renderModel(t, m), "format: email")
+}
+
+type Credentials struct {
+	ID       string `json:"id" openapi:",readOnly"`
+	Username string `json:"username"`
+	Password string `json:"password" openapi:",writeOnly"`
+}
+
+func TestModelFrom_ReadOnlyWriteOnly(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[Credentials]()
+	require.NoError(t, m.Err())
+
+	props := m.SchemaProxy.Schema().Properties
+
+	id := props.GetOrZero("id").Schema()
+	require.NotNil(t, id.ReadOnly)
+	assert.True(t, *id.ReadOnly)
+	assert.Nil(t, id.WriteOnly)
+
+	password := props.GetOrZero("password").Schema()
+	require.NotNil(t, password.WriteOnly)
+	assert.True(t, *password.WriteOnly)
+	assert.Nil(t, password.ReadOnly)
+
+	username := props.GetOrZero("username").Schema()
+	assert.Nil(t, username.ReadOnly)
+	assert.Nil(t, username.WriteOnly)
+
+	rend := renderModel(t, m)
+	assert.Contains(t, rend, "readOnly: true")
+	assert.Contains(t, rend, "writeOnly: true")
+}
+
+type Nickname struct {
+	Name     string  `json:"name"`
+	Nickname *string `json:"nickname"`
+	Alias    string  `json:"alias" openapi:",nullable"`
+}
+
+func TestModelFrom_NullablePointers(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[Nickname]()
+	require.NoError(t, m.Err())
+
+	props := m.SchemaProxy.Schema().Properties
+	assert.Equal(t, []string{"string"}, props.GetOrZero("name").Schema().Type)
+	assert.Equal(t, []string{"string"}, props.GetOrZero("nickname").Schema().Type)
+	assert.Equal(t, []string{"string", "null"}, props.GetOrZero("alias").Schema().Type)
+
+	m = arrest.ModelFrom[Nickname](arrest.WithNullablePointers())
+	require.NoError(t, m.Err())
+
+	props = m.SchemaProxy.Schema().Properties
+	assert.Equal(t, []string{"string"}, props.GetOrZero("name").Schema().Type)
+	assert.Equal(t, []string{"string", "null"}, props.GetOrZero("nickname").Schema().Type)
+
+	assert.Contains(t, renderModel(t, m), "null")
+}
+
+type Keeper struct {
+	Name string `json:"name"`
+}
+
+type Kennel struct {
+	Keeper   Keeper  `json:"keeper" openapi:",refName=Keeper,readOnly"`
+	Previous *Keeper `json:"previous" openapi:",refName=Keeper,nullable"`
+	Backup   *Keeper `json:"backup" openapi:",refName=Keeper"`
+	Helper   Keeper  `json:"helper" openapi:",refName=Keeper"`
+}
+
+func TestModelFrom_RefNameUseSiteProps(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[Kennel]()
+	require.NoError(t, m.Err())
+
+	keeper, found := m.ExtractChildRefs()["github.com/zostay/arrest-go_test.Keeper"]
+	require.True(t, found)
+	assert.Equal(t, []string{"object"}, keeper.Schema().Type)
+	assert.Nil(t, keeper.Schema().ReadOnly)
+
+	const ref = "#/components/schemas/github.com/zostay/arrest-go_test.Keeper"
+
+	props := m.SchemaProxy.Schema().Properties
+
+	owner := props.GetOrZero("keeper")
+	require.False(t, owner.IsReference())
+	require.NotNil(t, owner.Schema().ReadOnly)
+	assert.True(t, *owner.Schema().ReadOnly)
+	require.Len(t, owner.Schema().AllOf, 1)
+	assert.Equal(t, ref, owner.Schema().AllOf[0].GetReference())
+
+	previous := props.GetOrZero("previous")
+	require.False(t, previous.IsReference())
+	assert.Empty(t, previous.Schema().Type)
+	require.Len(t, previous.Schema().AnyOf, 2)
+	assert.Equal(t, ref, previous.Schema().AnyOf[0].GetReference())
+	assert.Equal(t, []string{"null"}, previous.Schema().AnyOf[1].Schema().Type)
+
+	assert.Equal(t, ref, props.GetOrZero("backup").GetReference())
+	assert.Equal(t, ref, props.GetOrZero("helper").GetReference())
+
+	m = arrest.ModelFrom[Kennel](arrest.WithNullablePointers())
+	require.NoError(t, m.Err())
+
+	keeper = m.ExtractChildRefs()["github.com/zostay/arrest-go_test.Keeper"]
+	assert.Equal(t, []string{"object"}, keeper.Schema().Type)
+
+	backup := m.SchemaProxy.Schema().Properties.GetOrZero("backup")
+	require.False(t, backup.IsReference())
+	require.Len(t, backup.Schema().AnyOf, 2)
+	assert.Equal(t, ref, backup.Schema().AnyOf[0].GetReference())
+}
+
+type Person struct {
+	Name    string `json:"name"`
+	Address struct {
+		Street string `json:"street"`
+		City   string `json:"city"`
+	} `json:"address"`
+}
+
+func TestModelFrom_InlineStruct(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[Person]()
+	require.NoError(t, m.Err())
+
+	address := m.SchemaProxy.Schema().Properties.GetOrZero("address")
+	require.False(t, address.IsReference())
+	assert.Equal(t, []string{"object"}, address.Schema().Type)
+	assert.Equal(t, []string{"street", "city"},
+		slices.Collect(address.Schema().Properties.KeysFromOldest()))
+	assert.Empty(t, m.ExtractChildRefs())
+
+	m = arrest.ModelFrom[Person](arrest.WithHoistedInlineStructs())
+	require.NoError(t, m.Err())
+
+	address = m.SchemaProxy.Schema().Properties.GetOrZero("address")
+	require.True(t, address.IsReference())
+	assert.Equal(t, "#/components/schemas/github.com/zostay/arrest-go_test.PersonAddress", address.GetReference())
+
+	hoisted, found := m.ExtractChildRefs()["github.com/zostay/arrest-go_test.PersonAddress"]
+	require.True(t, found)
+	assert.Equal(t, []string{"street", "city"},
+		slices.Collect(hoisted.Schema().Properties.KeysFromOldest()))
+}
+
+type Commuter struct {
+	Home struct {
+		Geo struct {
+			Lat float64 `json:"lat"`
+		} `json:"geo"`
+	} `json:"home"`
+	Work struct {
+		Geo struct {
+			Lng float64 `json:"lng"`
+		} `json:"geo"`
+	} `json:"work"`
+}
+
+func TestModelFrom_NestedHoistedStructs(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[Commuter](arrest.WithHoistedInlineStructs())
+	require.NoError(t, m.Err())
+
+	const pkg = "github.com/zostay/arrest-go_test."
+	refs := m.ExtractChildRefs()
+	assert.ElementsMatch(t, []string{
+		pkg + "CommuterHome",
+		pkg + "CommuterHomeGeo",
+		pkg + "CommuterWork",
+		pkg + "CommuterWorkGeo",
+	}, slices.Collect(maps.Keys(refs)))
+
+	home := refs[pkg+"CommuterHome"].Schema().Properties.GetOrZero("geo")
+	assert.Equal(t, "#/components/schemas/"+pkg+"CommuterHomeGeo", home.GetReference())
+	work := refs[pkg+"CommuterWork"].Schema().Properties.GetOrZero("geo")
+	assert.Equal(t, "#/components/schemas/"+pkg+"CommuterWorkGeo", work.GetReference())
+
+	assert.Equal(t, []string{"lat"},
+		slices.Collect(refs[pkg+"CommuterHomeGeo"].Schema().Properties.KeysFromOldest()))
+	assert.Equal(t, []string{"lng"},
+		slices.Collect(refs[pkg+"CommuterWorkGeo"].Schema().Properties.KeysFromOldest()))
+}
+
+type SignupRequest struct {
+	Email    string `json:"email"`
+	Nickname string `json:"nickname,omitempty"`
+	Referrer string `json:"referrer,omitempty" openapi:",required"`
+	Source   string `json:"source" openapi:",required=false"`
+}
+
+func TestModelFrom_Required(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[SignupRequest]()
+	require.NoError(t, m.Err())
+
+	assert.Equal(t, []string{"email", "referrer"}, m.SchemaProxy.Schema().Required)
+
+	rend := renderModel(t, m)
+	assert.Contains(t, rend, "required:")
+	assert.Contains(t, rend, "- email")
+	assert.Contains(t, rend, "- referrer")
+	assert.NotContains(t, rend, "- nickname")
+	assert.NotContains(t, rend, "- source")
+}
+
+func TestModel_Enum(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[string]().Enum("red", "green", "blue")
+
+	enum := m.SchemaProxy.Schema().Enum
+	require.Len(t, enum, 3)
+	assert.Equal(t, "red", enum[0].Value)
+	assert.Equal(t, "blue", enum[2].Value)
+
+	rend := renderModel(t, m)
+	assert.Contains(t, rend, "enum:")
+	assert.Contains(t, rend, "- red")
+	assert.Contains(t, rend, "- green")
+	assert.Contains(t, rend, "- blue")
+}
+
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityHigh
+)
+
+type Color int
+
+const (
+	ColorRed Color = iota
+	ColorBlue
+)
+
+func (c Color) MarshalText() ([]byte, error) {
+	return []byte([]string{"red", "blue"}[c]), nil
+}
+
+type Size int
+
+const (
+	SizeSmall Size = iota
+	SizeLarge
+)
+
+func (s *Size) MarshalText() ([]byte, error) {
+	return []byte([]string{"small", "large"}[*s]), nil
+}
+
+func TestEnumFromValues(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.EnumFromValues(PriorityLow, PriorityHigh)
+	require.NoError(t, m.Err())
+
+	schema := m.SchemaProxy.Schema()
+	assert.Equal(t, []string{"integer"}, schema.Type)
+	require.Len(t, schema.Enum, 2)
+	assert.Equal(t, "!!int", schema.Enum[0].Tag)
+	assert.Equal(t, "0", schema.Enum[0].Value)
+	assert.Equal(t, "1", schema.Enum[1].Value)
+
+	m = arrest.EnumFromValues(ColorRed, ColorBlue)
+	require.NoError(t, m.Err())
+
+	schema = m.SchemaProxy.Schema()
+	assert.Equal(t, []string{"string"}, schema.Type)
+	assert.Empty(t, schema.Format)
+
+	rend := renderModel(t, m)
+	assert.Contains(t, rend, "- red")
+	assert.Contains(t, rend, "- blue")
+
+	m = arrest.EnumFromValues(SizeSmall, SizeLarge)
+	require.NoError(t, m.Err())
+
+	schema = m.SchemaProxy.Schema()
+	assert.Equal(t, []string{"string"}, schema.Type)
+	require.Len(t, schema.Enum, 2)
+	assert.Equal(t, "!!str", schema.Enum[0].Tag)
+	assert.Equal(t, "small", schema.Enum[0].Value)
+	assert.Equal(t, "large", schema.Enum[1].Value)
+}
+
+type RetryPolicy struct {
+	Attempts int           `json:"attempts"`
+	Backoff  time.Duration `json:"backoff"`
+}
+
+func TestModelFrom_Duration(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[RetryPolicy]()
+	require.NoError(t, m.Err())
+
+	backoff := m.SchemaProxy.Schema().Properties.GetOrZero("backoff").Schema()
+	assert.Equal(t, []string{"string"}, backoff.Type)
+	assert.Equal(t, "duration", backoff.Format)
+	assert.Contains(t, renderModel(t, m), "format: duration")
+
+	m = arrest.ModelFrom[RetryPolicy](arrest.WithDurationAsInteger())
+	require.NoError(t, m.Err())
+
+	backoff = m.SchemaProxy.Schema().Properties.GetOrZero("backoff").Schema()
+	assert.Equal(t, []string{"integer"}, backoff.Type)
+	assert.Equal(t, "int64", backoff.Format)
+}
+
+type Attachment struct {
+	Name     string   `json:"name"`
+	Data     []byte   `json:"data"`
+	Checksum [4]uint8 `json:"checksum"`
+}
+
+func TestModelFrom_Bytes(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[Attachment]()
+	require.NoError(t, m.Err())
+
+	props := m.SchemaProxy.Schema().Properties
+
+	data := props.GetOrZero("data").Schema()
+	assert.Equal(t, []string{"string"}, data.Type)
+	assert.Equal(t, "byte", data.Format)
+
+	checksum := props.GetOrZero("checksum").Schema()
+	assert.Equal(t, []string{"array"}, checksum.Type)
+
+	assert.Contains(t, renderModel(t, m), "format: byte")
+}
+
+func TestModelFrom_Inline(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.SchemaComponent("ListConnections",
+		arrest.ModelFrom[ListConnectionsResponse](arrest.WithInline()))
+	require.NoError(t, doc.Err())
+
+	schemas := doc.DataModel.Model.Components.Schemas
+	assert.Equal(t, []string{"ListConnections"}, slices.Collect(schemas.KeysFromOldest()))
+
+	connections := schemas.GetOrZero("ListConnections").Schema().Properties.GetOrZero("connections")
+	items := connections.Schema().Items.A
+	require.False(t, items.IsReference())
+	assert.Equal(t, []string{"object"}, items.Schema().Type)
+}
+
+type Version struct {
+	Major, Minor int
+}
+
+func (v Version) MarshalText() ([]byte, error) {
+	return []byte(strconv.Itoa(v.Major) + "." + strconv.Itoa(v.Minor)), nil
+}
+
+type Amount struct {
+	Cents int64
+}
+
+func (a *Amount) MarshalText() ([]byte, error) {
+	return []byte(strconv.FormatInt(a.Cents/100, 10)), nil
+}
+
+type Release struct {
+	Version Version  `json:"version"`
+	Price   Amount   `json:"price"`
+	Prior   *Version `json:"prior"`
+}
+
+func TestModelFrom_TextMarshaler(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[Release]()
+	require.NoError(t, m.Err())
+
+	props := m.SchemaProxy.Schema().Properties
+	assert.Equal(t, []string{"string"}, props.GetOrZero("version").Schema().Type)
+	assert.Nil(t, props.GetOrZero("version").Schema().Properties)
+	assert.Equal(t, []string{"string"}, props.GetOrZero("prior").Schema().Type)
+
+	arrest.RegisterScalarType(reflect.TypeOf(Amount{}), "number", "double")
+	t.Cleanup(func() { arrest.UnregisterScalarType(reflect.TypeOf(Amount{})) })
+
+	m = arrest.ModelFrom[Release]()
+	require.NoError(t, m.Err())
+
+	price := m.SchemaProxy.Schema().Properties.GetOrZero("price").Schema()
+	assert.Equal(t, []string{"number"}, price.Type)
+	assert.Equal(t, "double", price.Format)
+}
+
+type Decimal struct {
+	digits []byte
+	scale  int
+}
+
+type Invoice struct {
+	Total Decimal            `json:"total"`
+	Lines []Decimal          `json:"lines"`
+	Taxes map[string]Decimal `json:"taxes"`
+}
+
+func TestRegisterTypeSchema(t *testing.T) {
+	t.Parallel()
+
+	arrest.RegisterTypeSchema(reflect.TypeOf(Decimal{}), func() *base.Schema {
+		return &base.Schema{
+			Type:    []string{"string"},
+			Format:  "decimal",
+			Pattern: `^-?\d+(\.\d+)?$`,
+		}
+	})
+	t.Cleanup(func() { arrest.UnregisterTypeSchema(reflect.TypeOf(Decimal{})) })
+
+	m := arrest.ModelFrom[Decimal]()
+	require.NoError(t, m.Err())
+	assert.Equal(t, "decimal", m.SchemaProxy.Schema().Format)
+
+	m = arrest.ModelFrom[Invoice]()
+	require.NoError(t, m.Err())
+
+	props := m.SchemaProxy.Schema().Properties
+	assert.Equal(t, "decimal", props.GetOrZero("total").Schema().Format)
+	assert.Equal(t, "decimal", props.GetOrZero("lines").Schema().Items.A.Schema().Format)
+	assert.Equal(t, "decimal", props.GetOrZero("taxes").Schema().AdditionalProperties.A.Schema().Format)
+
+	assert.Contains(t, renderModel(t, m), "format: decimal")
+}
+
+type NestedA struct{ B NestedB }
+type NestedB struct{ C NestedC }
+type NestedC struct{ D NestedD }
+type NestedD struct{ Value string }
+
+type TreeNode struct {
+	Name     string     `json:"name"`
+	Children []TreeNode `json:"children"`
+}
+
+func TestModelFrom_MaxDepth(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[NestedA]()
+	require.NoError(t, m.Err())
+
+	m = arrest.ModelFrom[NestedA](arrest.WithMaxDepth(4))
+	assert.ErrorContains(t, m.Err(), arrest.ErrMaxDepthExceeded.Error())
+
+	m = arrest.ModelFrom[NestedA](arrest.WithMaxDepth(5))
+	assert.NoError(t, m.Err())
+
+	m = arrest.ModelFrom[TreeNode](arrest.WithMaxDepth(10))
+	assert.ErrorContains(t, m.Err(), arrest.ErrMaxDepthExceeded.Error())
+}
+
+func TestModelFrom_MapKeys(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[map[int]string]()
+	require.NoError(t, m.Err())
+
+	schema := m.SchemaProxy.Schema()
+	assert.Equal(t, []string{"object"}, schema.Type)
+	require.NotNil(t, schema.PropertyNames)
+	assert.Equal(t, "^-?[0-9]+$", schema.PropertyNames.Schema().Pattern)
+
+	m = arrest.ModelFrom[map[Version]string]()
+	require.NoError(t, m.Err())
+	assert.Nil(t, m.SchemaProxy.Schema().PropertyNames)
+
+	m = arrest.ModelFrom[map[struct{}]string]()
+	assert.ErrorIs(t, m.Err(), arrest.ErrUnsupportedModelType)
+	assert.Equal(t, []string{"object"}, m.SchemaProxy.Schema().Type)
+}
+
+func TestModelFrom_Closed(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[Person]()
+	require.NoError(t, m.Err())
+	assert.Nil(t, m.SchemaProxy.Schema().AdditionalProperties)
+
+	m = arrest.ModelFrom[Person](arrest.Closed())
+	require.NoError(t, m.Err())
+
+	for _, schema := range []*base.Schema{
+		m.SchemaProxy.Schema(),
+		m.SchemaProxy.Schema().Properties.GetOrZero("address").Schema(),
+	} {
+		require.NotNil(t, schema.AdditionalProperties)
+		assert.True(t, schema.AdditionalProperties.IsB())
+		assert.False(t, schema.AdditionalProperties.B)
+	}
+
+	assert.Contains(t, renderModel(t, m), "additionalProperties: false")
+}
+
+type Profile struct {
+	DisplayName string `json:"displayName" openapi:",title=Display Name"`
+	Location    string `json:"location" openapi:",title='City, Country'"`
+}
+
+func TestModelFrom_Title(t *testing.T) {
+	t.Parallel()
+
+	m := arrest.ModelFrom[Profile]()
+	require.NoError(t, m.Err())
+
+	props := m.SchemaProxy.Schema().Properties
+	assert.Equal(t, "Display Name", props.GetOrZero("displayName").Schema().Title)
+	assert.Equal(t, "City, Country", props.GetOrZero("location").Schema().Title)
+
+	rend := renderModel(t, m)
+	assert.Contains(t, rend, "title: Display Name")
+	assert.Contains(t, rend, "title: City, Country")
+}
+
+type Avatar struct {
+	Image string `json:"image" openapi:",content-type=image/png,content-encoding=base64"`
+}
+
+func TestModelFrom_ContentType(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.SchemaComponent("Avatar", arrest.ModelFrom[Avatar]())
+	require.NoError(t, doc.Err())
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), "contentMediaType: image/png")
+	assert.Contains(t, string(rend), "contentEncoding: base64")
+}
+
+func TestModel_Const(t *testing.T) {
+	t.Parallel()
+
+	rend := renderModel(t, arrest.ModelFrom[string]().Const("dog"))
+	assert.Contains(t, rend, "const: dog")
+
+	rend = renderModel(t, arrest.ModelFrom[int]().Const(42))
+	assert.Contains(t, rend, "const: 42")
+}
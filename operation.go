@@ -2,10 +2,14 @@ package arrest
 
 import (
 	"fmt"
+	"net/http"
+	"strconv"
 
 	"github.com/pb33f/libopenapi/datamodel/high/base"
 	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
 )
 
 // Operation provides DSL methods for creating OpenAPI operations.
@@ -15,7 +19,10 @@ type Operation struct {
 	ErrHelper
 }
 
-// RequestBody sets the request body for the operation.
+// RequestBody sets the content of the request body for the given media type.
+// Calling it again with another media type adds that content type alongside
+// the ones already defined, so the same body may be sent as JSON, XML, and so
+// on, each with its own schema.
 func (o *Operation) RequestBody(mt string, model *Model) *Operation {
 	if model.SchemaProxy == nil {
 		return withErr(o, fmt.Errorf("model must be initialized"))
@@ -37,6 +44,80 @@ func (o *Operation) RequestBody(mt string, model *Model) *Operation {
 	return o
 }
 
+// RequestBodyBinary sets the request body of the operation to raw bytes of the
+// given media type, such as application/octet-stream, documented as a string
+// with the binary format.
+func (o *Operation) RequestBodyBinary(mt string) *Operation {
+	return o.RequestBody(mt, &Model{
+		SchemaProxy: base.CreateSchemaProxy(&base.Schema{
+			Type:   []string{"string"},
+			Format: "binary",
+		}),
+	})
+}
+
+// RequestBodyRequired marks the request body of the operation as required.
+func (o *Operation) RequestBodyRequired() *Operation {
+	if o.Operation.RequestBody == nil {
+		o.Operation.RequestBody = &v3.RequestBody{}
+	}
+
+	required := true
+	o.Operation.RequestBody.Required = &required
+	return o
+}
+
+// RequestBodyDescription sets the description of the request body of the
+// operation.
+func (o *Operation) RequestBodyDescription(description string) *Operation {
+	if o.Operation.RequestBody == nil {
+		o.Operation.RequestBody = &v3.RequestBody{}
+	}
+
+	o.Operation.RequestBody.Description = description
+	return o
+}
+
+// RequestBodyRef sets the request body of the operation to a reference to the
+// request body component with the given name, which is added with
+// Document.RequestBodyComponent.
+func (o *Operation) RequestBodyRef(name string) *Operation {
+	o.Operation.RequestBody = &v3.RequestBody{}
+	setRefExtension(&o.Operation.RequestBody.Extensions, "#/components/requestBodies/"+name)
+
+	return o
+}
+
+// MaxRequestBytes documents the largest request body, in bytes, that the
+// operation accepts using the x-max-request-bytes extension.
+func (o *Operation) MaxRequestBytes(n int64) *Operation {
+	return o.byteLimit("x-max-request-bytes", n)
+}
+
+// MaxResponseBytes documents the largest response body, in bytes, that the
+// operation returns using the x-max-response-bytes extension.
+func (o *Operation) MaxResponseBytes(n int64) *Operation {
+	return o.byteLimit("x-max-response-bytes", n)
+}
+
+func (o *Operation) byteLimit(ext string, n int64) *Operation {
+	if n <= 0 {
+		return withErr(o, fmt.Errorf("%s must be greater than zero", ext))
+	}
+
+	return o.AddExtension(ext, utils.CreateIntNode(strconv.FormatInt(n, 10)))
+}
+
+// AddExtension adds a vendor extension to the operation. The name must begin
+// with "x-".
+func (o *Operation) AddExtension(name string, value *yaml.Node) *Operation {
+	if err := setExtension(&o.Operation.Extensions, name, value); err != nil {
+		return withErr(o, err)
+	}
+
+	return o
+}
+
 // Description sets the description for the operation.
 func (o *Operation) Description(description string) *Operation {
 	o.Operation.Description = description
@@ -49,6 +130,54 @@ func (o *Operation) Summary(summary string) *Operation {
 	return o
 }
 
+// ExternalDocs links the operation to additional documentation.
+func (o *Operation) ExternalDocs(url, description string) *Operation {
+	o.Operation.ExternalDocs = &base.ExternalDoc{
+		URL:         url,
+		Description: description,
+	}
+	return o
+}
+
+// Deprecated marks the operation as deprecated.
+func (o *Operation) Deprecated() *Operation {
+	deprecated := true
+	o.Operation.Deprecated = &deprecated
+	return o
+}
+
+// Sunset marks the operation as deprecated and documents a Sunset header, as
+// described by RFC 8594, on every response of the operation. The date is an
+// HTTP-date, such as "Sat, 31 Dec 2025 23:59:59 GMT", and is given as the
+// example value of the header. It only affects responses that have already
+// been defined, so call it after all the responses have been added.
+func (o *Operation) Sunset(date string) *Operation {
+	o.Deprecated()
+
+	if o.Operation.Responses == nil || o.Operation.Responses.Codes == nil {
+		return o
+	}
+
+	for _, res := range o.Operation.Responses.Codes.FromOldest() {
+		r := &Response{Response: res}
+		r.Header("Sunset", ModelFrom[string](), func(h *Header) {
+			h.Description("The date after which the operation will no longer be available.")
+			h.Header.Example = utils.CreateStringNode(date)
+		})
+
+		o.AddHandler(r)
+	}
+
+	return o
+}
+
+// AddServer adds a server URL that overrides the servers of the document for
+// this operation, such as a separate host for uploads.
+func (o *Operation) AddServer(url string) *Operation {
+	o.Operation.Servers = append(o.Operation.Servers, &v3.Server{URL: url})
+	return o
+}
+
 // OperationID sets the operation ID for the operation.
 func (o *Operation) OperationID(id string) *Operation {
 	o.Operation.OperationId = id
@@ -76,6 +205,104 @@ func (o *Operation) Parameters(ps *Parameters) *Operation {
 	return o
 }
 
+// WithIdempotencyKey documents an optional Idempotency-Key request header for
+// the operation. This is a convenience for write endpoints that allow clients
+// to safely retry requests.
+func (o *Operation) WithIdempotencyKey() *Operation {
+	return o.Parameters(NParameters(1).
+		P(0, func(p *Parameter) {
+			p.Name("Idempotency-Key").In("header").
+				Model(ModelFrom[string]()).
+				Description("A unique key identifying this request. When a request is retried with the same key, the server returns the original result rather than performing the operation again.")
+		}))
+}
+
+// WithOptimisticConcurrency documents an ETag based optimistic concurrency
+// flow for the operation. It adds a required If-Match request header and a 412
+// Precondition Failed response for when the entity tag no longer matches the
+// current state of the resource.
+func (o *Operation) WithOptimisticConcurrency() *Operation {
+	return o.Parameters(NParameters(1).
+		P(0, func(p *Parameter) {
+			p.Name("If-Match").In("header").Required().
+				Model(ModelFrom[string]()).
+				Description("The entity tag of the resource as last seen by the client. The request is only performed if it matches the current entity tag.")
+		})).
+		Response("412", func(r *Response) {
+			r.Description("The entity tag given in If-Match does not match the current state of the resource.")
+		})
+}
+
+// WithAsyncJob documents the operation as starting a long-running job. It adds
+// a 202 Accepted response whose body is the given job model and whose Location
+// header points to the endpoint the client may poll for the status of the job.
+func (o *Operation) WithAsyncJob(jobModel *Model) *Operation {
+	return o.Response("202", func(r *Response) {
+		r.Description("The request was accepted and will be processed asynchronously.").
+			Content("application/json", jobModel).
+			Header("Location", ModelFrom[string](), func(h *Header) {
+				h.Description("The URL of the endpoint that reports the status of the job.")
+			})
+	})
+}
+
+// WithServerSentEvents documents the operation as a stream of server-sent
+// events. It adds a 200 response with text/event-stream content described by
+// the given model, which is the schema of the data of each event.
+func (o *Operation) WithServerSentEvents(eventModel *Model) *Operation {
+	return o.Response("200", func(r *Response) {
+		r.Description("A stream of server-sent events.").
+			Content("text/event-stream", eventModel)
+	})
+}
+
+// WithRateLimitError documents a 429 Too Many Requests response for the
+// operation with the given error model as its body and a Retry-After header
+// giving the number of seconds the client should wait before trying again.
+func (o *Operation) WithRateLimitError(model *Model) *Operation {
+	return o.Response("429", func(r *Response) {
+		r.Description("Too many requests have been made. Wait before trying again.").
+			Content("application/json", model).
+			Header("Retry-After", ModelFrom[int](), func(h *Header) {
+				h.Description("The number of seconds to wait before making another request.")
+			})
+	})
+}
+
+// Callback documents a request the API makes back to the client as part of
+// this operation, such as a notification that an asynchronous job is done. The
+// name identifies the callback and the expression is a runtime expression
+// giving the URL of the request, such as "{$request.body#/callbackUrl}". The
+// callback is a POST operation that is configured by the given function.
+func (o *Operation) Callback(name, expression string, cb func(o *Operation)) *Operation {
+	if o.Operation.Callbacks == nil {
+		o.Operation.Callbacks = orderedmap.New[string, *v3.Callback]()
+	}
+
+	callback, hasCallback := o.Operation.Callbacks.Get(name)
+	if !hasCallback {
+		callback = &v3.Callback{Expression: orderedmap.New[string, *v3.PathItem]()}
+		o.Operation.Callbacks.Set(name, callback)
+	}
+
+	pi, hasPi := callback.Expression.Get(expression)
+	if !hasPi {
+		pi = &v3.PathItem{}
+		callback.Expression.Set(expression, pi)
+	}
+
+	if pi.Post == nil {
+		pi.Post = &v3.Operation{}
+	}
+
+	co := &Operation{Operation: pi.Post}
+	o.AddHandler(co)
+
+	cb(co)
+
+	return o
+}
+
 // Response adds a response to the operation.
 func (o *Operation) Response(code string, cb func(r *Response)) *Operation {
 	if o.Operation.Responses == nil {
@@ -96,6 +323,102 @@ func (o *Operation) Response(code string, cb func(r *Response)) *Operation {
 
 	cb(res)
 
+	// OpenAPI requires every response to have a description, so fill in a
+	// placeholder if the callback did not provide one. A reference takes its
+	// description from the component.
+	if res.Response.Description == "" && !res.isRef() {
+		res.Response.Description = defaultResponseDescription(code)
+	}
+
+	return o
+}
+
+// ResponseSame adds the same response to the operation for each of the given
+// codes. The callback is run once per code, so each code gets its own response
+// configured identically.
+func (o *Operation) ResponseSame(codes []string, cb func(r *Response)) *Operation {
+	for _, code := range codes {
+		o.Response(code, cb)
+	}
+
+	return o
+}
+
+// defaultResponseDescription returns the standard HTTP status text for the
+// given code or "Response" if the code is not a known status code.
+func defaultResponseDescription(code string) string {
+	if status, err := strconv.Atoi(code); err == nil {
+		if text := http.StatusText(status); text != "" {
+			return text
+		}
+	}
+
+	return "Response"
+}
+
+// ResponseExample attaches a named example value to the content of the
+// response with the given status code and media type. The value is encoded as
+// JSON, so struct fields are named by their json tags. The response and its
+// content must already have been defined with Response.
+func (o *Operation) ResponseExample(code, mt, name string, value any) *Operation {
+	var res *v3.Response
+	if o.Operation.Responses != nil && o.Operation.Responses.Codes != nil {
+		res = o.Operation.Responses.Codes.GetOrZero(code)
+	}
+
+	if res == nil {
+		return withErr(o, fmt.Errorf("no response defined for code %q", code))
+	}
+
+	var media *v3.MediaType
+	if res.Content != nil {
+		media = res.Content.GetOrZero(mt)
+	}
+
+	if media == nil {
+		return withErr(o, fmt.Errorf("no %q content defined for response %q", mt, code))
+	}
+
+	node, err := jsonValueNode(value)
+	if err != nil {
+		return withErr(o, err)
+	}
+
+	if media.Examples == nil {
+		media.Examples = orderedmap.New[string, *base.Example]()
+	}
+
+	media.Examples.Set(name, &base.Example{Value: node})
+
+	return o
+}
+
+// RequestBodyExampleValue attaches a named example to the content of the
+// request body with the given media type. The value is encoded with
+// encoding/json, so a typed Go value produces exactly the example a client
+// would send. The request body and its content must already have been defined
+// with RequestBody.
+func (o *Operation) RequestBodyExampleValue(mt, name string, value any) *Operation {
+	var media *v3.MediaType
+	if o.Operation.RequestBody != nil && o.Operation.RequestBody.Content != nil {
+		media = o.Operation.RequestBody.Content.GetOrZero(mt)
+	}
+
+	if media == nil {
+		return withErr(o, fmt.Errorf("no %q content defined for request body", mt))
+	}
+
+	node, err := jsonValueNode(value)
+	if err != nil {
+		return withErr(o, err)
+	}
+
+	if media.Examples == nil {
+		media.Examples = orderedmap.New[string, *base.Example]()
+	}
+
+	media.Examples.Set(name, &base.Example{Value: node})
+
 	return o
 }
 
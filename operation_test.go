@@ -0,0 +1,480 @@
+package arrest_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zostay/arrest-go"
+)
+
+func TestOperation_ResponseDefaultDescription(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Get("/things").
+		Response("200", func(r *arrest.Response) {
+			r.Content("application/json", arrest.ModelFrom[string]())
+		}).
+		Response("default", func(r *arrest.Response) {})
+
+	require.NoError(t, doc.Err())
+
+	ops := doc.Operations(context.Background())
+	require.Len(t, ops, 1)
+
+	codes := ops[0].Operation.Responses.Codes
+	assert.Equal(t, "OK", codes.GetOrZero("200").Description)
+	assert.Equal(t, "Response", codes.GetOrZero("default").Description)
+}
+
+func TestOperation_WithIdempotencyKey(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Post("/things").
+		WithIdempotencyKey().
+		Response("201", func(r *arrest.Response) {
+			r.Description("Created")
+		})
+
+	require.NoError(t, doc.Err())
+
+	rend, err := doc.OpenAPI.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), "name: Idempotency-Key")
+	assert.Contains(t, string(rend), "in: header")
+}
+
+type ExampleThing struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	OwnerID string `json:"ownerId,omitempty"`
+}
+
+func TestOperation_ResponseExample(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Get("/things/{id}").
+		Response("200", func(r *arrest.Response) {
+			r.Content("application/json", arrest.ModelFrom[ExampleThing]())
+		}).
+		Response("404", func(r *arrest.Response) {
+			r.Content("application/json", arrest.ModelFrom[ErrorPayload]())
+		}).
+		ResponseExample("200", "application/json", "found",
+			map[string]any{"id": "1", "name": "Widget"}).
+		ResponseExample("200", "application/json", "owned",
+			ExampleThing{ID: "2", Name: "Gadget", OwnerID: "u7"}).
+		ResponseExample("404", "application/json", "missing",
+			map[string]any{"code": "not_found", "message": "no such thing"})
+
+	require.NoError(t, doc.Err())
+
+	ops := doc.Operations(context.Background())
+	require.Len(t, ops, 1)
+
+	codes := ops[0].Operation.Responses.Codes
+	found := codes.GetOrZero("200").Content.GetOrZero("application/json").Examples
+	missing := codes.GetOrZero("404").Content.GetOrZero("application/json").Examples
+
+	assert.Equal(t, []string{"found", "owned"}, slices.Collect(found.KeysFromOldest()))
+	assert.Equal(t, []string{"missing"}, slices.Collect(missing.KeysFromOldest()))
+
+	rend, err := doc.OpenAPI.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), "name: Widget")
+	assert.Contains(t, string(rend), "ownerId: u7")
+	assert.NotContains(t, string(rend), "ownerid")
+	assert.Contains(t, string(rend), "message: no such thing")
+
+	doc.Get("/other").ResponseExample("200", "application/json", "nope", "x")
+	assert.Error(t, doc.Err())
+}
+
+func TestOperation_MaxBytes(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Post("/uploads").
+		MaxRequestBytes(1 << 20).
+		MaxResponseBytes(4096)
+
+	require.NoError(t, doc.Err())
+
+	rend, err := doc.OpenAPI.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), "x-max-request-bytes: 1048576")
+	assert.Contains(t, string(rend), "x-max-response-bytes: 4096")
+
+	doc.Post("/other").MaxRequestBytes(0)
+	assert.Error(t, doc.Err())
+}
+
+func TestOperation_WithOptimisticConcurrency(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Put("/things/{id}").
+		WithOptimisticConcurrency().
+		Response("200", func(r *arrest.Response) {
+			r.Description("Updated.")
+		})
+
+	require.NoError(t, doc.Err())
+
+	ops := doc.Operations(context.Background())
+	require.Len(t, ops, 1)
+
+	params := ops[0].Operation.Parameters
+	require.Len(t, params, 1)
+	assert.Equal(t, "If-Match", params[0].Name)
+	assert.Equal(t, "header", params[0].In)
+	require.NotNil(t, params[0].Required)
+	assert.True(t, *params[0].Required)
+
+	_, has412 := ops[0].Operation.Responses.Codes.Get("412")
+	assert.True(t, has412)
+
+	rend, err := doc.OpenAPI.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), "name: If-Match")
+	assert.Contains(t, string(rend), `"412":`)
+}
+
+func TestOperation_ResponseSame(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Get("/things/{id}").
+		ResponseSame([]string{"200", "203"}, func(r *arrest.Response) {
+			r.Description("The thing.").
+				Content("application/json", arrest.ModelFrom[ExampleThing]())
+		})
+
+	require.NoError(t, doc.Err())
+
+	ops := doc.Operations(context.Background())
+	require.Len(t, ops, 1)
+
+	codes := ops[0].Operation.Responses.Codes
+	assert.Equal(t, []string{"200", "203"}, slices.Collect(codes.KeysFromOldest()))
+
+	ok, err := codes.GetOrZero("200").Render()
+	require.NoError(t, err)
+	nonAuthoritative, err := codes.GetOrZero("203").Render()
+	require.NoError(t, err)
+	assert.Equal(t, string(ok), string(nonAuthoritative))
+	assert.Contains(t, string(ok), "description: The thing.")
+}
+
+type ExampleJob struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+func TestOperation_WithAsyncJob(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Post("/reports").
+		WithAsyncJob(arrest.ModelFrom[ExampleJob]())
+
+	require.NoError(t, doc.Err())
+
+	ops := doc.Operations(context.Background())
+	require.Len(t, ops, 1)
+
+	accepted := ops[0].Operation.Responses.Codes.GetOrZero("202")
+	require.NotNil(t, accepted)
+
+	location := accepted.Headers.GetOrZero("Location")
+	require.NotNil(t, location)
+	assert.Equal(t, []string{"string"}, location.Schema.Schema().Type)
+
+	job := accepted.Content.GetOrZero("application/json")
+	require.NotNil(t, job)
+	assert.Equal(t, []string{"id", "status"},
+		slices.Collect(job.Schema.Schema().Properties.KeysFromOldest()))
+
+	rend, err := doc.OpenAPI.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), `"202":`)
+	assert.Contains(t, string(rend), "Location:")
+}
+
+func TestOperation_RequestBodyExampleValue(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	example := ExampleJob{ID: "42", Status: "queued"}
+
+	doc.Post("/jobs").
+		RequestBody("application/json", arrest.ModelFrom[ExampleJob]()).
+		RequestBodyExampleValue("application/json", "queued", example)
+
+	require.NoError(t, doc.Err())
+
+	ops := doc.Operations(context.Background())
+	require.Len(t, ops, 1)
+
+	examples := ops[0].Operation.RequestBody.Content.GetOrZero("application/json").Examples
+	require.NotNil(t, examples)
+
+	var decoded ExampleJob
+	require.NoError(t, examples.GetOrZero("queued").Value.Decode(&decoded))
+	assert.Equal(t, example, decoded)
+
+	rend, err := doc.OpenAPI.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), "id: \"42\"")
+	assert.Contains(t, string(rend), "status: queued")
+
+	doc.Post("/other").RequestBodyExampleValue("application/json", "nope", example)
+	assert.Error(t, doc.Err())
+}
+
+func TestOperation_WithRateLimitError(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Get("/things").
+		WithRateLimitError(arrest.ModelFrom[ErrorPayload]())
+
+	require.NoError(t, doc.Err())
+
+	ops := doc.Operations(context.Background())
+	require.Len(t, ops, 1)
+
+	tooMany := ops[0].Operation.Responses.Codes.GetOrZero("429")
+	require.NotNil(t, tooMany)
+
+	retryAfter := tooMany.Headers.GetOrZero("Retry-After")
+	require.NotNil(t, retryAfter)
+	assert.Equal(t, []string{"integer"}, retryAfter.Schema.Schema().Type)
+
+	body := tooMany.Content.GetOrZero("application/json")
+	require.NotNil(t, body)
+	assert.Equal(t, []string{"code", "message"},
+		slices.Collect(body.Schema.Schema().Properties.KeysFromOldest()))
+
+	rend, err := doc.OpenAPI.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), `"429":`)
+	assert.Contains(t, string(rend), "Retry-After:")
+}
+
+func TestOperation_WithServerSentEvents(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Get("/jobs/{id}/events").
+		WithServerSentEvents(arrest.ModelFrom[ExampleJob]())
+
+	require.NoError(t, doc.Err())
+
+	ops := doc.Operations(context.Background())
+	require.Len(t, ops, 1)
+
+	events := ops[0].Operation.Responses.Codes.GetOrZero("200").Content.GetOrZero("text/event-stream")
+	require.NotNil(t, events)
+	assert.Equal(t, []string{"id", "status"},
+		slices.Collect(events.Schema.Schema().Properties.KeysFromOldest()))
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), "text/event-stream:")
+}
+
+func TestOperation_Sunset(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Get("/v1/widgets").
+		Response("200", func(r *arrest.Response) {
+			r.Description("The widgets.")
+		}).
+		Response("404", func(r *arrest.Response) {}).
+		Sunset("Sat, 31 Dec 2025 23:59:59 GMT")
+
+	require.NoError(t, doc.Err())
+
+	ops := doc.Operations(context.Background())
+	require.Len(t, ops, 1)
+
+	op := ops[0].Operation
+	require.NotNil(t, op.Deprecated)
+	assert.True(t, *op.Deprecated)
+
+	for _, code := range []string{"200", "404"} {
+		sunset := op.Responses.Codes.GetOrZero(code).Headers.GetOrZero("Sunset")
+		require.NotNil(t, sunset, code)
+		assert.Equal(t, "Sat, 31 Dec 2025 23:59:59 GMT", sunset.Example.Value)
+	}
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), "deprecated: true")
+	assert.Contains(t, string(rend), "Sunset:")
+}
+
+func TestOperation_RequestBodyRef(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.RequestBodyComponent("JobInput", "application/json", arrest.ModelFrom[ExampleJob]())
+
+	doc.Post("/jobs").RequestBodyRef("JobInput")
+	doc.Put("/jobs/{id}").RequestBodyRef("JobInput")
+
+	require.NoError(t, doc.Err())
+
+	input := doc.DataModel.Model.Components.RequestBodies.GetOrZero("JobInput")
+	require.NotNil(t, input)
+	require.NotNil(t, input.Content.GetOrZero("application/json"))
+
+	assert.Equal(t, []string{"#/components/requestBodies/JobInput"},
+		doc.References(context.Background()))
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), `post:
+            requestBody:
+                $ref: '#/components/requestBodies/JobInput'`)
+	assert.Contains(t, string(rend), `put:
+            requestBody:
+                $ref: '#/components/requestBodies/JobInput'`)
+}
+
+func TestOperation_RequestBodyRequired(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Post("/jobs").
+		RequestBody("application/json", arrest.ModelFrom[ExampleJob]()).
+		RequestBodyRequired().
+		RequestBodyDescription("The job to start.")
+
+	require.NoError(t, doc.Err())
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), `requestBody:
+                description: The job to start.`)
+	assert.Contains(t, string(rend), "required: true")
+}
+
+func TestOperation_RequestBodyBinary(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Post("/uploads").
+		RequestBodyBinary("application/octet-stream")
+
+	require.NoError(t, doc.Err())
+
+	ops := doc.Operations(context.Background())
+	require.Len(t, ops, 1)
+
+	body := ops[0].Operation.RequestBody.Content.GetOrZero("application/octet-stream")
+	require.NotNil(t, body)
+	assert.Equal(t, []string{"string"}, body.Schema.Schema().Type)
+	assert.Equal(t, "binary", body.Schema.Schema().Format)
+}
+
+func TestOperation_RequestBody_MultipleContentTypes(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Post("/jobs").
+		RequestBody("application/json", arrest.ModelFrom[ExampleJob]()).
+		RequestBody("application/xml", arrest.ModelFrom[string]())
+
+	require.NoError(t, doc.Err())
+
+	ops := doc.Operations(context.Background())
+	require.Len(t, ops, 1)
+
+	content := ops[0].Operation.RequestBody.Content
+	assert.Equal(t, []string{"application/json", "application/xml"},
+		slices.Collect(content.KeysFromOldest()))
+	assert.Equal(t, []string{"object"}, content.GetOrZero("application/json").Schema.Schema().Type)
+	assert.Equal(t, []string{"string"}, content.GetOrZero("application/xml").Schema.Schema().Type)
+}
+
+func TestOperation_AddServer(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.AddServer("https://api.example.com")
+	doc.Post("/uploads").
+		AddServer("https://uploads.example.com").
+		Response("201", func(r *arrest.Response) {})
+
+	require.NoError(t, doc.Err())
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), `            servers:
+                - url: https://uploads.example.com`)
+}
+
+func TestOperation_Callback(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Post("/jobs").
+		Callback("jobFinished", "{$request.body#/callbackUrl}", func(o *arrest.Operation) {
+			o.RequestBody("application/json", arrest.ModelFrom[ExampleJob]()).
+				Response("200", func(r *arrest.Response) {
+					r.Description("The notification was received.")
+				})
+		}).
+		Response("202", func(r *arrest.Response) {})
+
+	require.NoError(t, doc.Err())
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), "callbacks:")
+	assert.Contains(t, string(rend), "jobFinished:")
+	assert.Contains(t, string(rend), "{$request.body#/callbackUrl}")
+	assert.Contains(t, string(rend), "description: The notification was received.")
+}
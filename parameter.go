@@ -3,9 +3,13 @@ package arrest
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
+	"strings"
+	"unicode"
 
 	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/utils"
 )
 
 // ErrUnsupportedParameterType is returned when a parameter is created from an
@@ -16,6 +20,8 @@ var ErrUnsupportedParameterType = errors.New("unsupported parameter type")
 type Parameter struct {
 	Parameter *v3.Parameter
 
+	goType reflect.Type
+
 	ErrHelper
 }
 
@@ -30,6 +36,7 @@ type Parameters struct {
 func ParameterFromReflect(t reflect.Type) *Parameter {
 	p := &Parameter{
 		Parameter: &v3.Parameter{},
+		goType:    t,
 	}
 
 	m := ModelFromReflect(t)
@@ -149,6 +156,58 @@ func NParameters(n int) *Parameters {
 	return ps
 }
 
+// ParameterRefs creates a new Parameters holding a reference to each of the
+// named parameter components, which are added with Document.ParameterComponent:
+//
+//	o.Parameters(arrest.ParameterRefs("Limit", "Offset"))
+func ParameterRefs(names ...string) *Parameters {
+	ps := &Parameters{
+		Parameters: make([]*Parameter, len(names)),
+	}
+
+	for i, name := range names {
+		p := &v3.Parameter{}
+		setRefExtension(&p.Extensions, "#/components/parameters/"+name)
+		ps.Parameters[i] = &Parameter{Parameter: p}
+	}
+
+	return ps
+}
+
+// StandardPaginationParameters creates the query parameters commonly accepted
+// by list endpoints: page, the 1-based page number defaulting to 1; pageSize,
+// the number of items per page from 1 to 100 defaulting to 20; and cursor, an
+// opaque token for continuing from a previous page. Attach them with
+// Operation.Parameters:
+//
+//	doc.Get("/pets").Parameters(arrest.StandardPaginationParameters())
+func StandardPaginationParameters() *Parameters {
+	minPage, minPageSize, maxPageSize := 1.0, 1.0, 100.0
+
+	page := ModelFrom[int32]()
+	page.SchemaProxy.Schema().Minimum = &minPage
+	page.SchemaProxy.Schema().Default = utils.CreateIntNode("1")
+
+	pageSize := ModelFrom[int32]()
+	pageSize.SchemaProxy.Schema().Minimum = &minPageSize
+	pageSize.SchemaProxy.Schema().Maximum = &maxPageSize
+	pageSize.SchemaProxy.Schema().Default = utils.CreateIntNode("20")
+
+	return NParameters(3).
+		P(0, func(p *Parameter) {
+			p.Name("page").In("query").Model(page).
+				Description("The page of results to return, starting from 1.")
+		}).
+		P(1, func(p *Parameter) {
+			p.Name("pageSize").In("query").Model(pageSize).
+				Description("The maximum number of results to return per page.")
+		}).
+		P(2, func(p *Parameter) {
+			p.Name("cursor").In("query").Model(ModelFrom[string]()).
+				Description("An opaque token returned with a previous page to continue from where it left off.")
+		})
+}
+
 // P returns the parameter at the given index and calls the callback with it.
 func (p *Parameters) P(idx int, cb func(p *Parameter)) *Parameters {
 	cb(p.Parameters[idx])
@@ -184,5 +243,92 @@ func (p *Parameter) Description(description string) *Parameter {
 func (p *Parameter) Model(m *Model) *Parameter {
 	p.AddHandler(m)
 	p.Parameter.Schema = m.SchemaProxy
+	p.goType = m.goType
 	return p
 }
+
+// ToInputStruct synthesizes a struct type with one field per parameter. Each
+// field has the Go type the parameter was generated from and is tagged with the
+// name and location of the parameter, so passing the type back to
+// ParametersFromReflect documents the same parameters. This is useful for
+// binding the parameters of a function-derived parameter list at runtime.
+//
+// Parameters with the same name in different locations, such as an id path
+// parameter and an id query parameter, are told apart by prefixing the field
+// name of the later one with its location, as in "QueryId".
+//
+// It returns an error if any parameter has no name, was not generated from a
+// Go type, is listed twice in the same location, or would still share a field
+// name with another parameter.
+func (p *Parameters) ToInputStruct() (reflect.Type, error) {
+	fields := make([]reflect.StructField, 0, len(p.Parameters))
+	params := make(map[string]struct{}, len(p.Parameters))
+	fieldNames := make(map[string]struct{}, len(p.Parameters))
+	for i, param := range p.Parameters {
+		name := param.Parameter.Name
+		if name == "" {
+			return nil, fmt.Errorf("parameter %d has no name", i)
+		}
+
+		if param.goType == nil {
+			return nil, fmt.Errorf("parameter %q has no Go type", name)
+		}
+
+		in := param.Parameter.In
+		if in == "" {
+			in = "query"
+		}
+
+		if _, dup := params[in+":"+name]; dup {
+			return nil, fmt.Errorf("parameter %q in %s is listed more than once", name, in)
+		}
+
+		params[in+":"+name] = struct{}{}
+
+		fieldName := goFieldName(name, i)
+		if _, dup := fieldNames[fieldName]; dup {
+			fieldName = goFieldName(in+"-"+name, i)
+		}
+
+		if _, dup := fieldNames[fieldName]; dup {
+			return nil, fmt.Errorf("parameter %q in %s has the same field name %q as another parameter", name, in, fieldName)
+		}
+
+		fieldNames[fieldName] = struct{}{}
+
+		fields = append(fields, reflect.StructField{
+			Name: fieldName,
+			Type: param.goType,
+			Tag:  reflect.StructTag(fmt.Sprintf(`json:%q openapi:%q`, name, name+",in="+in)),
+		})
+	}
+
+	return reflect.StructOf(fields), nil
+}
+
+// goFieldName turns a parameter name like "pet-id" into an exported Go
+// identifier like "PetId".
+func goFieldName(name string, idx int) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+
+		b.WriteRune(r)
+	}
+
+	fieldName := b.String()
+	if fieldName == "" || !unicode.IsUpper([]rune(fieldName)[0]) {
+		fieldName = fmt.Sprintf("P%d%s", idx, fieldName)
+	}
+
+	return fieldName
+}
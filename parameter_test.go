@@ -0,0 +1,201 @@
+package arrest_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zostay/arrest-go"
+)
+
+type ThingStatus string
+
+const (
+	ThingStatusOpen   ThingStatus = "open"
+	ThingStatusClosed ThingStatus = "closed"
+)
+
+type ListThingsParams struct {
+	Status ThingStatus `json:"status"`
+	Limit  int32       `json:"limit"`
+}
+
+func TestParametersFrom_RegisteredEnum(t *testing.T) {
+	t.Parallel()
+
+	statusType := reflect.TypeOf(ThingStatusOpen)
+	err := arrest.RegisterEnum(statusType, ThingStatusOpen, ThingStatusClosed)
+	require.NoError(t, err)
+	t.Cleanup(func() { arrest.UnregisterEnum(statusType) })
+
+	ps := arrest.ParametersFrom[ListThingsParams]()
+	require.NoError(t, ps.Err())
+	require.Len(t, ps.Parameters, 2)
+
+	status := ps.Parameters[0].Parameter
+	assert.Equal(t, "status", status.Name)
+	assert.Equal(t, "query", status.In)
+
+	enum := status.Schema.Schema().Enum
+	require.Len(t, enum, 2)
+	assert.Equal(t, "open", enum[0].Value)
+	assert.Equal(t, "closed", enum[1].Value)
+
+	assert.Empty(t, ps.Parameters[1].Parameter.Schema.Schema().Enum)
+}
+
+func ShowThing(ctx context.Context, thingID string, verbose bool) (*ListThingsParams, error) {
+	return nil, nil
+}
+
+func TestParameters_ToInputStruct(t *testing.T) {
+	t.Parallel()
+
+	ps := arrest.ParametersFromReflect(reflect.TypeOf(ShowThing)).
+		P(0, func(p *arrest.Parameter) {
+			p.Name("thing-id").In("path").Required()
+		}).
+		P(1, func(p *arrest.Parameter) {
+			p.Name("verbose").In("query")
+		})
+	require.NoError(t, ps.Err())
+
+	typ, err := ps.ToInputStruct()
+	require.NoError(t, err)
+	require.Equal(t, 2, typ.NumField())
+
+	assert.Equal(t, "ThingId", typ.Field(0).Name)
+	assert.Equal(t, reflect.TypeOf(""), typ.Field(0).Type)
+	assert.Equal(t, "thing-id,in=path", typ.Field(0).Tag.Get("openapi"))
+
+	assert.Equal(t, "Verbose", typ.Field(1).Name)
+	assert.Equal(t, reflect.TypeOf(true), typ.Field(1).Type)
+	assert.Equal(t, "verbose,in=query", typ.Field(1).Tag.Get("openapi"))
+
+	again := arrest.ParametersFromReflect(typ)
+	require.NoError(t, again.Err())
+	require.Len(t, again.Parameters, 2)
+	assert.Equal(t, "thing-id", again.Parameters[0].Parameter.Name)
+	assert.Equal(t, "path", again.Parameters[0].Parameter.In)
+	assert.Equal(t, "verbose", again.Parameters[1].Parameter.Name)
+	assert.Equal(t, "query", again.Parameters[1].Parameter.In)
+
+	_, err = arrest.NParameters(1).ToInputStruct()
+	assert.Error(t, err)
+
+	ps = arrest.ParametersFromReflect(reflect.TypeOf(ShowThing)).
+		P(0, func(p *arrest.Parameter) {
+			p.Name("id").In("path").Required()
+		}).
+		P(1, func(p *arrest.Parameter) {
+			p.Name("id").In("query")
+		})
+	require.NoError(t, ps.Err())
+
+	typ, err = ps.ToInputStruct()
+	require.NoError(t, err)
+	require.Equal(t, 2, typ.NumField())
+
+	assert.Equal(t, "Id", typ.Field(0).Name)
+	assert.Equal(t, "id,in=path", typ.Field(0).Tag.Get("openapi"))
+	assert.Equal(t, "QueryId", typ.Field(1).Name)
+	assert.Equal(t, "id,in=query", typ.Field(1).Tag.Get("openapi"))
+
+	ps = arrest.ParametersFromReflect(reflect.TypeOf(ShowThing)).
+		P(0, func(p *arrest.Parameter) {
+			p.Name("id").In("query")
+		}).
+		P(1, func(p *arrest.Parameter) {
+			p.Name("id").In("query")
+		})
+	require.NoError(t, ps.Err())
+
+	_, err = ps.ToInputStruct()
+	assert.Error(t, err)
+}
+
+func TestParameterRefs(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.ParameterComponent("Limit", func(p *arrest.Parameter) {
+		p.Name("limit").In("query").
+			Model(arrest.ModelFrom[int32]()).
+			Description("The maximum number of items to return.")
+	})
+
+	doc.Get("/things").
+		Parameters(arrest.ParameterRefs("Limit"))
+	doc.Get("/others").
+		Parameters(arrest.ParameterRefs("Limit"))
+
+	require.NoError(t, doc.Err())
+
+	limit := doc.DataModel.Model.Components.Parameters.GetOrZero("Limit")
+	require.NotNil(t, limit)
+	assert.Equal(t, "limit", limit.Name)
+
+	assert.Equal(t, []string{"#/components/parameters/Limit"},
+		doc.References(context.Background()))
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), `parameters:
+                - $ref: '#/components/parameters/Limit'`)
+}
+
+func TestStandardPaginationParameters(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Get("/things").
+		Parameters(arrest.StandardPaginationParameters())
+
+	require.NoError(t, doc.Err())
+
+	ops := doc.Operations(context.Background())
+	require.Len(t, ops, 1)
+
+	params := ops[0].Operation.Parameters
+	require.Len(t, params, 3)
+	assert.Equal(t, "page", params[0].Name)
+	assert.Equal(t, "pageSize", params[1].Name)
+	assert.Equal(t, "cursor", params[2].Name)
+	for _, p := range params {
+		assert.Equal(t, "query", p.In)
+	}
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), "minimum: 1")
+	assert.Contains(t, string(rend), "maximum: 100")
+	assert.Contains(t, string(rend), "default: 20")
+}
+
+type VersionedListParams struct {
+	APIVersion string `json:"X-Api-Version" openapi:",in=header"`
+	Limit      int32  `json:"limit"`
+}
+
+func TestParametersFrom_Header(t *testing.T) {
+	t.Parallel()
+
+	ps := arrest.ParametersFrom[VersionedListParams]()
+	require.NoError(t, ps.Err())
+	require.Len(t, ps.Parameters, 2)
+
+	version := ps.Parameters[0].Parameter
+	assert.Equal(t, "X-Api-Version", version.Name)
+	assert.Equal(t, "header", version.In)
+	assert.Nil(t, version.Required)
+
+	limit := ps.Parameters[1].Parameter
+	assert.Equal(t, "limit", limit.Name)
+	assert.Equal(t, "query", limit.In)
+}
@@ -0,0 +1,31 @@
+package arrest
+
+// ProblemDetails is the standard shape of an error response as described by
+// RFC 7807. It is returned with the application/problem+json media type.
+type ProblemDetails struct {
+	// Type is a URI reference that identifies the problem type.
+	Type string `json:"type,omitempty" openapi:",format=uri-reference"`
+
+	// Title is a short, human-readable summary of the problem type.
+	Title string `json:"title,omitempty"`
+
+	// Status is the HTTP status code generated by the origin server for this
+	// occurrence of the problem.
+	Status int `json:"status,omitempty"`
+
+	// Detail is a human-readable explanation specific to this occurrence of
+	// the problem.
+	Detail string `json:"detail,omitempty"`
+
+	// Instance is a URI reference that identifies the specific occurrence of
+	// the problem.
+	Instance string `json:"instance,omitempty" openapi:",format=uri-reference"`
+}
+
+// ProblemDetailsModel returns a Model describing ProblemDetails, generated with
+// the given options. Use it as the content of error responses:
+//
+//	r.Content("application/problem+json", arrest.ProblemDetailsModel())
+func ProblemDetailsModel(opts ...ModelOption) *Model {
+	return ModelFrom[ProblemDetails](opts...)
+}
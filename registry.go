@@ -0,0 +1,166 @@
+package arrest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	registryLock   sync.RWMutex
+	enumRegistry   = map[reflect.Type][]*yaml.Node{}
+	scalarRegistry = map[reflect.Type]scalarType{}
+	schemaRegistry = map[reflect.Type]func() *base.Schema{}
+)
+
+// scalarType is the type and format of a scalar schema.
+type scalarType struct {
+	typ    string
+	format string
+}
+
+// valueNodes encodes each of the given values as a YAML node.
+func valueNodes(values ...any) ([]*yaml.Node, error) {
+	nodes := make([]*yaml.Node, 0, len(values))
+	for _, value := range values {
+		node := &yaml.Node{}
+		if err := node.Encode(value); err != nil {
+			return nil, fmt.Errorf("failed to encode value %v: %w", value, err)
+		}
+		nodes = append(nodes, node)
+	}
+
+	return nodes, nil
+}
+
+// jsonValueNode encodes the value as JSON, just as it would be sent over the
+// wire, and returns the result as a YAML node.
+func jsonValueNode(value any) (*yaml.Node, error) {
+	bs, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal value as JSON: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(bs, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON value: %w", err)
+	}
+
+	node := doc.Content[0]
+	clearStyle(node)
+
+	return node, nil
+}
+
+// clearStyle removes the JSON flow and quoting styles from a decoded node so
+// that it renders like the rest of the document.
+func clearStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, c := range node.Content {
+		clearStyle(c)
+	}
+}
+
+// RegisterEnum registers the allowed values of a named Go type. Whenever a
+// schema is generated for that type, it will include an enum listing these
+// values. This bridges Go typed constants to OpenAPI enums without having to
+// tag every field that uses the type:
+//
+//	type Status string
+//
+//	const (
+//		StatusActive   Status = "active"
+//		StatusInactive Status = "inactive"
+//	)
+//
+//	func init() {
+//		_ = arrest.RegisterEnum(reflect.TypeOf(StatusActive), StatusActive, StatusInactive)
+//	}
+//
+// It is safe to call this from package init and from multiple goroutines.
+func RegisterEnum(t reflect.Type, values ...any) error {
+	nodes, err := valueNodes(values...)
+	if err != nil {
+		return err
+	}
+
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	enumRegistry[t] = nodes
+	return nil
+}
+
+// registeredEnum returns the enum values registered for the given type, if
+// any.
+func registeredEnum(t reflect.Type) ([]*yaml.Node, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	nodes, isEnum := enumRegistry[t]
+	return nodes, isEnum
+}
+
+// RegisterScalarType registers the schema type and format to use for a Go type
+// that is encoded as a scalar. This overrides the schema that would otherwise
+// be inferred for the type. For example, types implementing
+// encoding.TextMarshaler are described as strings by default, but one that
+// marshals to a number may be registered like this:
+//
+//	arrest.RegisterScalarType(reflect.TypeOf(Amount{}), "number", "double")
+//
+// The format may be empty. It is safe to call this from package init and from
+// multiple goroutines.
+func RegisterScalarType(t reflect.Type, typ, format string) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	scalarRegistry[t] = scalarType{typ: typ, format: format}
+}
+
+// registeredScalarType returns the scalar type registered for the given type,
+// if any.
+func registeredScalarType(t reflect.Type) (scalarType, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	scalar, isScalar := scalarRegistry[t]
+	return scalar, isScalar
+}
+
+// RegisterTypeSchema registers a function that builds the schema for a Go type.
+// Whenever a schema is needed for that type, whether directly or for a field,
+// element, or value of another type, the function is called instead of
+// inspecting the type with reflection. This is useful for third-party types
+// that arrest cannot describe correctly:
+//
+//	arrest.RegisterTypeSchema(reflect.TypeOf(uuid.UUID{}), func() *base.Schema {
+//		return &base.Schema{Type: []string{"string"}, Format: "uuid"}
+//	})
+//
+// The function must return a new schema on each call. It is safe to call this
+// from package init and from multiple goroutines.
+func RegisterTypeSchema(t reflect.Type, schema func() *base.Schema) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	schemaRegistry[t] = schema
+}
+
+// registeredTypeSchema returns a new schema for the given type if a schema
+// function has been registered for it.
+func registeredTypeSchema(t reflect.Type) (*base.Schema, bool) {
+	registryLock.RLock()
+	schema, hasSchema := schemaRegistry[t]
+	registryLock.RUnlock()
+
+	if !hasSchema {
+		return nil, false
+	}
+
+	return schema(), true
+}
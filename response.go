@@ -1,6 +1,9 @@
 package arrest
 
 import (
+	"maps"
+	"slices"
+
 	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 	"github.com/pb33f/libopenapi/orderedmap"
 )
@@ -18,7 +21,8 @@ func (r *Response) Description(description string) *Response {
 	return r
 }
 
-// Header adds a header to the response.
+// Header adds a header to the response. The model may be any schema, including
+// arrays and objects, such as a Link header documented as a list of strings.
 func (r *Response) Header(name string, m *Model, mods ...func(h *Header)) *Response {
 	if r.Response.Headers == nil {
 		r.Response.Headers = orderedmap.New[string, *v3.Header]()
@@ -27,7 +31,7 @@ func (r *Response) Header(name string, m *Model, mods ...func(h *Header)) *Respo
 	hdr := &v3.Header{}
 	r.Response.Headers.Set(name, hdr)
 
-	m.AddHandler(m)
+	r.AddHandler(m)
 	hdr.Schema = m.SchemaProxy
 
 	if len(mods) > 0 {
@@ -46,7 +50,52 @@ func (r *Response) Content(code string, m *Model) *Response {
 		r.Response.Content = orderedmap.New[string, *v3.MediaType]()
 	}
 
-	m.AddHandler(m)
+	r.AddHandler(m)
 	r.Response.Content.Set(code, &v3.MediaType{Schema: m.SchemaProxy})
 	return r
 }
+
+// ContentRef adds a content type to the response whose schema is a reference
+// to the named schema component. It is a shorthand for:
+//
+//	r.Content(mt, arrest.SchemaRef(componentName))
+func (r *Response) ContentRef(mt, componentName string) *Response {
+	return r.Content(mt, SchemaRef(componentName))
+}
+
+// Link adds a link from the response to the operation with the given operation
+// ID. The params map the parameters of the linked operation to runtime
+// expressions giving their values, such as "$response.body#/id". The
+// parameters are added in sorted order.
+func (r *Response) Link(name, operationID string, params map[string]string) *Response {
+	if r.Response.Links == nil {
+		r.Response.Links = orderedmap.New[string, *v3.Link]()
+	}
+
+	link := &v3.Link{OperationId: operationID}
+	if len(params) > 0 {
+		link.Parameters = orderedmap.New[string, string]()
+		for _, param := range slices.Sorted(maps.Keys(params)) {
+			link.Parameters.Set(param, params[param])
+		}
+	}
+
+	r.Response.Links.Set(name, link)
+	return r
+}
+
+// ResponseRef returns a callback for Operation.Response that makes the
+// response a reference to the response component with the given name, which is
+// added with Document.ResponseComponent:
+//
+//	o.Response("404", arrest.ResponseRef("NotFound"))
+func ResponseRef(name string) func(r *Response) {
+	return func(r *Response) {
+		setRefExtension(&r.Response.Extensions, "#/components/responses/"+name)
+	}
+}
+
+// isRef returns true if the response is a reference to a response component.
+func (r *Response) isRef() bool {
+	return r.Response.Extensions != nil && r.Response.Extensions.GetOrZero("$ref") != nil
+}
@@ -0,0 +1,165 @@
+package arrest_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zostay/arrest-go"
+)
+
+func TestResponse_HeaderArray(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Get("/things").
+		Response("200", func(r *arrest.Response) {
+			r.Description("The things").
+				Header("Link", arrest.ModelFrom[[]string](), func(h *arrest.Header) {
+					h.Description("Links to related pages")
+				}).
+				Header("X-Rate", arrest.ModelFrom[map[string]int]())
+		})
+
+	require.NoError(t, doc.Err())
+
+	ops := doc.Operations(context.Background())
+	require.Len(t, ops, 1)
+
+	headers := ops[0].Operation.Responses.Codes.GetOrZero("200").Headers
+	link := headers.GetOrZero("Link")
+	require.NotNil(t, link)
+	assert.Equal(t, []string{"array"}, link.Schema.Schema().Type)
+	assert.Equal(t, []string{"string"}, link.Schema.Schema().Items.A.Schema().Type)
+
+	rate := headers.GetOrZero("X-Rate")
+	require.NotNil(t, rate)
+	assert.Equal(t, []string{"object"}, rate.Schema.Schema().Type)
+
+	rend, err := doc.OpenAPI.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), "type: array")
+	assert.Contains(t, string(rend), "additionalProperties:")
+}
+
+func TestProblemDetailsModel(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Get("/things").
+		Response("default", func(r *arrest.Response) {
+			r.Content("application/problem+json", arrest.ProblemDetailsModel())
+		})
+
+	require.NoError(t, doc.Err())
+
+	ops := doc.Operations(context.Background())
+	require.Len(t, ops, 1)
+
+	problem := ops[0].Operation.Responses.Codes.GetOrZero("default").Content.GetOrZero("application/problem+json")
+	require.NotNil(t, problem)
+	assert.Equal(t, []string{"type", "title", "status", "detail", "instance"},
+		slices.Collect(problem.Schema.Schema().Properties.KeysFromOldest()))
+
+	rend, err := doc.OpenAPI.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), "application/problem+json:")
+	assert.Contains(t, string(rend), "format: uri-reference")
+}
+
+func TestResponseRef(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.ResponseComponent("NotFound", func(r *arrest.Response) {
+		r.Description("The resource was not found.").
+			Content("application/json", arrest.ModelFrom[arrest.ProblemDetails]())
+	})
+
+	doc.Get("/pets/{id}").
+		Response("200", func(r *arrest.Response) {}).
+		Response("404", arrest.ResponseRef("NotFound"))
+	doc.Get("/owners/{id}").
+		Response("200", func(r *arrest.Response) {}).
+		Response("404", arrest.ResponseRef("NotFound"))
+
+	require.NoError(t, doc.Err())
+
+	notFound := doc.DataModel.Model.Components.Responses.GetOrZero("NotFound")
+	require.NotNil(t, notFound)
+	assert.Equal(t, "The resource was not found.", notFound.Description)
+
+	assert.Equal(t, []string{"#/components/responses/NotFound"},
+		doc.References(context.Background()))
+
+	for _, op := range doc.Operations(context.Background()) {
+		assert.Empty(t, op.Operation.Responses.Codes.GetOrZero("404").Description)
+	}
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), `"404":
+                    $ref: '#/components/responses/NotFound'`)
+	assert.Contains(t, string(rend), `responses:
+        NotFound:
+            description: The resource was not found.`)
+}
+
+func TestResponse_ContentRef(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.SchemaComponent("ErrorPayload", arrest.ModelFrom[ErrorPayload]())
+
+	doc.Get("/things").
+		Response("500", func(r *arrest.Response) {
+			r.ContentRef("application/json", "ErrorPayload")
+		})
+
+	require.NoError(t, doc.Err())
+
+	ops := doc.Operations(context.Background())
+	require.Len(t, ops, 1)
+
+	content := ops[0].Operation.Responses.Codes.GetOrZero("500").Content.GetOrZero("application/json")
+	require.NotNil(t, content)
+	assert.Equal(t, "#/components/schemas/ErrorPayload", content.Schema.GetReference())
+}
+
+func TestResponse_Link(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.Get("/pets/{id}").
+		OperationID("getPet").
+		Response("200", func(r *arrest.Response) {})
+	doc.Post("/pets").
+		OperationID("createPet").
+		Response("201", func(r *arrest.Response) {
+			r.Link("GetPet", "getPet", map[string]string{
+				"id": "$response.body#/id",
+			})
+		})
+
+	require.NoError(t, doc.Err())
+
+	rend, err := doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rend), `links:
+                        GetPet:
+                            operationId: getPet
+                            parameters:
+                                id: $response.body#/id`)
+}
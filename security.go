@@ -1,6 +1,8 @@
 package arrest
 
 import (
+	"fmt"
+
 	highv3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 	"github.com/pb33f/libopenapi/orderedmap"
 	"gopkg.in/yaml.v3"
@@ -8,6 +10,10 @@ import (
 
 type SecurityScheme struct {
 	SecurityScheme *highv3.SecurityScheme
+
+	// componentName is the name the scheme was registered with using
+	// Document.SecuritySchemeComponent.
+	componentName string
 }
 
 func SecuritySchemeForType(typ string) *SecurityScheme {
@@ -161,6 +167,44 @@ func (s *SecurityScheme) BearerFormat(format string) *SecurityScheme {
 	return s
 }
 
+// Requirement returns a security requirement for this scheme with the given
+// scopes, suitable for passing to Document.AddSecurityRequirement. The scheme
+// must already be registered with Document.SecuritySchemeComponent so that its
+// name is known. If the scheme defines OAuth2 flows, every scope must be
+// defined by at least one of them or an error is returned.
+func (s *SecurityScheme) Requirement(scopes ...string) (map[string][]string, error) {
+	if s.componentName == "" {
+		return nil, fmt.Errorf("security scheme must be added to the document as a component first")
+	}
+
+	if flows := s.SecurityScheme.Flows; flows != nil {
+		for _, scope := range scopes {
+			if !flowsHaveScope(scope, flows.Implicit, flows.Password, flows.ClientCredentials, flows.AuthorizationCode) {
+				return nil, fmt.Errorf("security scheme %q does not define the scope %q", s.componentName, scope)
+			}
+		}
+	}
+
+	return map[string][]string{
+		s.componentName: append([]string{}, scopes...),
+	}, nil
+}
+
+// flowsHaveScope returns true if any of the given flows defines the scope.
+func flowsHaveScope(scope string, flows ...*highv3.OAuthFlow) bool {
+	for _, flow := range flows {
+		if flow == nil || flow.Scopes == nil {
+			continue
+		}
+
+		if _, hasScope := flow.Scopes.Get(scope); hasScope {
+			return true
+		}
+	}
+
+	return false
+}
+
 type regardingFlow struct {
 	securityScheme *SecurityScheme
 	flow           []*highv3.OAuthFlow
@@ -0,0 +1,80 @@
+package arrest_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zostay/arrest-go"
+)
+
+type AuthConfig struct {
+	// Bearer is a JWT issued by the login service.
+	Bearer string `openapi:"bearerAuth,type=http,scheme=bearer,bearerFormat=JWT"`
+
+	// Key is an API key issued to the client.
+	Key string `openapi:"apiKey,type=apiKey,in=header,name=X-API-Key"`
+
+	Other string
+}
+
+func TestDocument_SecuritySchemesFrom(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	doc.SecuritySchemesFrom(AuthConfig{})
+	require.NoError(t, doc.Err())
+
+	schemes := doc.DataModel.Model.Components.SecuritySchemes
+	assert.Equal(t, 2, schemes.Len())
+
+	bearer := schemes.GetOrZero("bearerAuth")
+	require.NotNil(t, bearer)
+	assert.Equal(t, "http", bearer.Type)
+	assert.Equal(t, "bearer", bearer.Scheme)
+	assert.Equal(t, "JWT", bearer.BearerFormat)
+	assert.Equal(t, "bearerAuth is a JWT issued by the login service.", bearer.Description)
+
+	key := schemes.GetOrZero("apiKey")
+	require.NotNil(t, key)
+	assert.Equal(t, "apiKey", key.Type)
+	assert.Equal(t, "header", key.In)
+	assert.Equal(t, "X-API-Key", key.Name)
+	assert.Equal(t, "apiKey is an API key issued to the client.", key.Description)
+
+	doc.SecuritySchemesFrom("nope")
+	assert.Error(t, doc.Err())
+}
+
+func TestSecurityScheme_Requirement(t *testing.T) {
+	t.Parallel()
+
+	doc, err := arrest.NewDocument("test")
+	require.NoError(t, err)
+
+	oauth := arrest.SecuritySchemeOAuth2AuthorizationCode(
+		"https://example.com/authorize",
+		"https://example.com/token",
+		map[string]string{
+			"things:read":  "Read things.",
+			"things:write": "Write things.",
+		},
+	)
+
+	_, err = oauth.Requirement("things:read")
+	assert.Error(t, err)
+
+	doc.SecuritySchemeComponent("oauth", oauth)
+
+	req, err := oauth.Requirement("things:read")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"oauth": {"things:read"}}, req)
+
+	_, err = oauth.Requirement("things:read", "things:delete")
+	assert.Error(t, err)
+
+	doc.AddSecurityRequirement(req)
+	require.NoError(t, doc.Err())
+}
@@ -1,7 +1,9 @@
 package arrest
 
 import (
+	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -27,10 +29,69 @@ func (tag JSONTag) Name() string {
 	return strings.TrimSpace(parts[0])
 }
 
+// IsOmitEmpty returns true if the tag has the omitempty option.
+func (tag JSONTag) IsOmitEmpty() bool {
+	parts := tag.Parts()
+	for _, part := range parts[1:] {
+		if strings.TrimSpace(part) == "omitempty" {
+			return true
+		}
+	}
+	return false
+}
+
 type OpenAPITag string
 
+// Parts splits the tag on commas. Commas inside single quotes do not split the
+// tag, so a prop value containing commas, such as a regular expression, may be
+// quoted:
+//
+//	`openapi:",pattern='^[a-z]{1,3}$'"`
+//
+// A quote only opens a quoted value right after the = of a prop and only if
+// the value has a closing quote at the end, so an apostrophe elsewhere, as in
+// `openapi:",title=Owner's count,maxLength=5"`, is kept as is.
 func (tag OpenAPITag) Parts() []string {
-	return strings.Split(string(tag), ",")
+	var (
+		parts  []string
+		part   strings.Builder
+		quoted bool
+	)
+
+	runes := []rune(string(tag))
+	for i, r := range runes {
+		switch {
+		case r == '\'' && !quoted:
+			quoted = i > 0 && runes[i-1] == '=' && hasClosingQuote(runes[i+1:])
+			part.WriteRune(r)
+		case r == '\'' && quoted:
+			quoted = !isValueEnd(runes, i+1)
+			part.WriteRune(r)
+		case r == ',' && !quoted:
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteRune(r)
+		}
+	}
+
+	return append(parts, part.String())
+}
+
+// hasClosingQuote returns true if the runes following an opening quote contain
+// a quote that ends the value.
+func hasClosingQuote(runes []rune) bool {
+	for i, r := range runes {
+		if r == '\'' && isValueEnd(runes, i+1) {
+			return true
+		}
+	}
+	return false
+}
+
+// isValueEnd returns true if the prop value ends at the given index.
+func isValueEnd(runes []rune, i int) bool {
+	return i == len(runes) || runes[i] == ','
 }
 
 func (tag OpenAPITag) IsIgnored() bool {
@@ -53,9 +114,8 @@ func (tag OpenAPITag) Props() map[string]string {
 	props := make(map[string]string)
 	parts := tag.Parts()
 	for _, part := range parts[1:] {
-		pair := strings.Split(part, "=")
-		if len(pair) == 2 {
-			props[strings.TrimSpace(pair[0])] = strings.TrimSpace(pair[1])
+		if key, value, hasValue := strings.Cut(part, "="); hasValue {
+			props[strings.TrimSpace(key)] = unquoteProp(strings.TrimSpace(value))
 			continue
 		}
 		props[strings.TrimSpace(part)] = "true"
@@ -63,6 +123,14 @@ func (tag OpenAPITag) Props() map[string]string {
 	return props
 }
 
+// unquoteProp strips the single quotes from a quoted prop value.
+func unquoteProp(value string) string {
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
 type TagInfo struct {
 	jsonTag    JSONTag
 	openAPITag OpenAPITag
@@ -96,6 +164,28 @@ func (info *TagInfo) Name() string {
 	return ""
 }
 
+// IsOmitEmpty returns true if the json tag has the omitempty option.
+func (info *TagInfo) IsOmitEmpty() bool {
+	return info.jsonTag.IsOmitEmpty()
+}
+
+// IsRequired returns true if the field must be present. A field is required
+// when its json tag does not have the omitempty option. This may be overridden
+// with the required prop, as in `openapi:",required"` or
+// `openapi:",required=false"`.
+func (info *TagInfo) IsRequired() (bool, error) {
+	required, err := info.propBool("required")
+	if err != nil {
+		return false, err
+	}
+
+	if required != nil {
+		return *required, nil
+	}
+
+	return !info.IsOmitEmpty(), nil
+}
+
 func (info *TagInfo) Props() map[string]string {
 	return info.openAPITag.Props()
 }
@@ -119,3 +209,182 @@ func (info *TagInfo) HasIn() bool {
 func (into *TagInfo) In() string {
 	return into.Props()["in"]
 }
+
+// propInt parses the named prop as an integer. It returns nil if the prop is
+// not set.
+func (info *TagInfo) propInt(name string) (*int64, error) {
+	value, hasProp := info.Props()[name]
+	if !hasProp {
+		return nil, nil
+	}
+
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", name, value, err)
+	}
+
+	return &i, nil
+}
+
+// propFloat parses the named prop as a number. It returns nil if the prop is
+// not set.
+func (info *TagInfo) propFloat(name string) (*float64, error) {
+	value, hasProp := info.Props()[name]
+	if !hasProp {
+		return nil, nil
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", name, value, err)
+	}
+
+	return &f, nil
+}
+
+// propBool parses the named prop as a boolean. A prop given without a value is
+// true. It returns nil if the prop is not set.
+func (info *TagInfo) propBool(name string) (*bool, error) {
+	value, hasProp := info.Props()[name]
+	if !hasProp {
+		return nil, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", name, value, err)
+	}
+
+	return &b, nil
+}
+
+// MinLength returns the minLength prop or nil if it is not set.
+func (info *TagInfo) MinLength() (*int64, error) {
+	return info.propInt("minLength")
+}
+
+// MaxLength returns the maxLength prop or nil if it is not set.
+func (info *TagInfo) MaxLength() (*int64, error) {
+	return info.propInt("maxLength")
+}
+
+// Minimum returns the minimum prop or nil if it is not set.
+func (info *TagInfo) Minimum() (*float64, error) {
+	return info.propFloat("minimum")
+}
+
+// Maximum returns the maximum prop or nil if it is not set.
+func (info *TagInfo) Maximum() (*float64, error) {
+	return info.propFloat("maximum")
+}
+
+// ExclusiveMinimum returns the exclusiveMinimum prop or nil if it is not set.
+func (info *TagInfo) ExclusiveMinimum() (*float64, error) {
+	return info.propFloat("exclusiveMinimum")
+}
+
+// ExclusiveMaximum returns the exclusiveMaximum prop or nil if it is not set.
+func (info *TagInfo) ExclusiveMaximum() (*float64, error) {
+	return info.propFloat("exclusiveMaximum")
+}
+
+// MultipleOf returns the multipleOf prop or nil if it is not set. OpenAPI
+// requires multipleOf to be strictly positive, so any other value is an error.
+func (info *TagInfo) MultipleOf() (*float64, error) {
+	multipleOf, err := info.propFloat("multipleOf")
+	if err != nil {
+		return nil, err
+	}
+
+	if multipleOf != nil && *multipleOf <= 0 {
+		return nil, fmt.Errorf("invalid multipleOf %v: must be greater than zero", *multipleOf)
+	}
+
+	return multipleOf, nil
+}
+
+// MinItems returns the minItems prop or nil if it is not set.
+func (info *TagInfo) MinItems() (*int64, error) {
+	return info.propInt("minItems")
+}
+
+// MaxItems returns the maxItems prop or nil if it is not set.
+func (info *TagInfo) MaxItems() (*int64, error) {
+	return info.propInt("maxItems")
+}
+
+// UniqueItems returns the uniqueItems prop or nil if it is not set.
+func (info *TagInfo) UniqueItems() (*bool, error) {
+	return info.propBool("uniqueItems")
+}
+
+// MinProperties returns the minProperties prop or nil if it is not set.
+func (info *TagInfo) MinProperties() (*int64, error) {
+	return info.propInt("minProperties")
+}
+
+// MaxProperties returns the maxProperties prop or nil if it is not set.
+func (info *TagInfo) MaxProperties() (*int64, error) {
+	return info.propInt("maxProperties")
+}
+
+// Pattern returns the pattern prop. Since patterns often contain commas, the
+// value may be wrapped in single quotes.
+func (info *TagInfo) Pattern() string {
+	return info.Props()["pattern"]
+}
+
+// Default returns the default prop and whether it is set. The value is
+// returned as written and is converted to match the schema type when the
+// schema is generated.
+func (info *TagInfo) Default() (string, bool) {
+	value, hasDefault := info.Props()["default"]
+	return value, hasDefault
+}
+
+// Example returns the example prop and whether it is set. Like the default,
+// the value is converted to match the schema type when the schema is
+// generated.
+func (info *TagInfo) Example() (string, bool) {
+	value, hasExample := info.Props()["example"]
+	return value, hasExample
+}
+
+// Format returns the format prop, which sets the format of the schema without
+// changing its type.
+func (info *TagInfo) Format() string {
+	return info.Props()["format"]
+}
+
+// ReadOnly returns the readOnly prop or nil if it is not set.
+func (info *TagInfo) ReadOnly() (*bool, error) {
+	return info.propBool("readOnly")
+}
+
+// WriteOnly returns the writeOnly prop or nil if it is not set.
+func (info *TagInfo) WriteOnly() (*bool, error) {
+	return info.propBool("writeOnly")
+}
+
+// Nullable returns the nullable prop or nil if it is not set.
+func (info *TagInfo) Nullable() (*bool, error) {
+	return info.propBool("nullable")
+}
+
+// Title returns the title prop. A title containing commas must be wrapped in
+// single quotes, as in `openapi:",title='Name, Display'"`.
+func (info *TagInfo) Title() string {
+	return info.Props()["title"]
+}
+
+// ContentType returns the content-type prop, which is the media type of the
+// content of a string, such as image/png.
+func (info *TagInfo) ContentType() string {
+	return info.Props()["content-type"]
+}
+
+// ContentEncoding returns the content-encoding prop, which is the encoding
+// used to store binary content in a string, such as base64.
+func (info *TagInfo) ContentEncoding() string {
+	return info.Props()["content-encoding"]
+}
@@ -0,0 +1,52 @@
+package arrest_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zostay/arrest-go"
+)
+
+func TestOpenAPITag_Props(t *testing.T) {
+	t.Parallel()
+
+	tag := arrest.OpenAPITag(`code,pattern='^[A-Z]{2,3}$',minLength=2,uniqueItems`)
+	assert.Equal(t, "code", tag.Name())
+	assert.Equal(t, map[string]string{
+		"pattern":     "^[A-Z]{2,3}$",
+		"minLength":   "2",
+		"uniqueItems": "true",
+	}, tag.Props())
+
+	tag = arrest.OpenAPITag(`,pattern=^a=b$`)
+	assert.Equal(t, "", tag.Name())
+	assert.Equal(t, map[string]string{"pattern": "^a=b$"}, tag.Props())
+
+	tag = arrest.OpenAPITag(`,title=Owner's count,maxLength=5`)
+	assert.Equal(t, map[string]string{
+		"title":     "Owner's count",
+		"maxLength": "5",
+	}, tag.Props())
+
+	tag = arrest.OpenAPITag(`,title='Owner's pets, all of them',maxLength=5`)
+	assert.Equal(t, map[string]string{
+		"title":     "Owner's pets, all of them",
+		"maxLength": "5",
+	}, tag.Props())
+
+	tag = arrest.OpenAPITag(`,title='unclosed,maxLength=5`)
+	assert.Equal(t, map[string]string{
+		"title":     "'unclosed",
+		"maxLength": "5",
+	}, tag.Props())
+}
+
+func TestJSONTag_IsOmitEmpty(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, arrest.JSONTag("name,omitempty").IsOmitEmpty())
+	assert.True(t, arrest.JSONTag(",string,omitempty").IsOmitEmpty())
+	assert.False(t, arrest.JSONTag("name").IsOmitEmpty())
+	assert.False(t, arrest.JSONTag("omitempty").IsOmitEmpty())
+	assert.False(t, arrest.JSONTag("").IsOmitEmpty())
+}